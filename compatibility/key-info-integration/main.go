@@ -0,0 +1,179 @@
+//go:build integration
+// +build integration
+
+// Checks the key derivation against a live avalanchego node, by importing
+// a freshly generated key into the node keystore and comparing the X/P/C
+// addresses that the node reports with the ones derived by "pkg/keyinfo".
+// The node must have the keystore API enabled ("--api-keystore-enabled").
+// It is skipped when no endpoint is configured.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/google/uuid"
+	"github.com/gyuho/avalanche-ops/compatibility/pkg/keyinfo"
+)
+
+// endpointEnv is the environment variable for the node endpoint,
+// used when "--endpoint" is not set.
+const endpointEnv = "AVALANCHEGO_ENDPOINT"
+
+// go run -tags integration main.go --endpoint http://127.0.0.1:9650
+// AVALANCHEGO_ENDPOINT=http://127.0.0.1:9650 go run -tags integration main.go
+func main() {
+	fs := flag.NewFlagSet("key-info-integration", flag.ExitOnError)
+	endpoint := fs.String("endpoint", os.Getenv(endpointEnv), "avalanchego HTTP endpoint (default $"+endpointEnv+")")
+	timeout := fs.Duration("timeout", 30*time.Second, "timeout for all node API calls")
+	_ = fs.Parse(os.Args[1:])
+
+	if *endpoint == "" {
+		fmt.Printf("SKIP: no avalanchego endpoint (set --endpoint or $%s)\n", endpointEnv)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	if err := run(ctx, strings.TrimSuffix(*endpoint, "/")); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("SUCCESS")
+}
+
+func run(ctx context.Context, endpoint string) error {
+	var networkIDResp struct {
+		NetworkID string `json:"networkID"`
+	}
+	if err := call(ctx, endpoint+"/ext/info", "info.getNetworkID", struct{}{}, &networkIDResp); err != nil {
+		return err
+	}
+	networkID, err := strconv.ParseUint(networkIDResp.NetworkID, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid network ID %q from the node (%w)", networkIDResp.NetworkID, err)
+	}
+	hrp := constants.GetHRP(uint32(networkID))
+	fmt.Printf("network: %d, HRP %q\n", networkID, hrp)
+
+	pk, err := keyinfo.NewPrivateKey()
+	if err != nil {
+		return err
+	}
+	ki, err := keyinfo.NewInfoFromPrivateKeyWithHRP(pk, uint32(networkID), hrp)
+	if err != nil {
+		return err
+	}
+
+	// a throwaway keystore user for the generated key
+	user := userPass{Username: "key-info-integration-" + uuid.New().String(), Password: uuid.New().String()}
+	if err := call(ctx, endpoint+"/ext/keystore", "keystore.createUser", user, nil); err != nil {
+		return err
+	}
+	defer func() {
+		if err := call(context.Background(), endpoint+"/ext/keystore", "keystore.deleteUser", user, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to delete the keystore user %q (%v)\n", user.Username, err)
+		}
+	}()
+
+	req := importKeyRequest{userPass: user, PrivateKey: ki.PrivateKey}
+	var errs []string
+	for _, chain := range []struct {
+		path     string
+		method   string
+		expected string
+	}{
+		{"/ext/bc/X", "avm.importKey", ki.XAddress},
+		{"/ext/bc/P", "platform.importKey", ki.PAddress},
+		{"/ext/bc/C/avax", "avax.importKey", ki.CAddress},
+	} {
+		var resp struct {
+			Address string `json:"address"`
+		}
+		if err := call(ctx, endpoint+chain.path, chain.method, req, &resp); err != nil {
+			return err
+		}
+		if resp.Address != chain.expected {
+			errs = append(errs, fmt.Sprintf("%s: node %q, derived %q", chain.method, resp.Address, chain.expected))
+			continue
+		}
+		fmt.Printf("%s: %s\n", chain.method, resp.Address)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("node addresses do not match the derived addresses:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+type userPass struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type importKeyRequest struct {
+	userPass
+	PrivateKey string `json:"privateKey"`
+}
+
+// call makes the JSON-RPC 2.0 request to the node API,
+// and decodes the result into "result" if not nil.
+func call(ctx context.Context, url string, method string, params interface{}, result interface{}) error {
+	b, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s failed (%w)", method, err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s failed with %s (%s)", method, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var rpcResp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &rpcResp); err != nil {
+		return fmt.Errorf("%s returned an invalid response (%w)", method, err)
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("%s failed with code %d (%s)", method, rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+	if result == nil {
+		return nil
+	}
+	if len(rpcResp.Result) == 0 {
+		return errors.New(method + " returned no result")
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
@@ -0,0 +1,121 @@
+//go:build balance
+// +build balance
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gyuho/avalanche-ops/compatibility/pkg/keyinfo"
+)
+
+// go run -tags balance . ../../artifacts/ewoq.key.json 9999 --check-balance
+// go run -tags balance . ../../artifacts/ewoq.key.json 1 --check-balance --endpoint https://api.avax.network
+func init() {
+	balanceFetcher = fetchBalances
+}
+
+// maxAPIResponseSize is the maximum size of an API response body to read.
+const maxAPIResponseSize = 1 << 20
+
+// fetchBalances fetches the AVAX balances of the X and P-chain addresses,
+// and of the eth address on the C-chain, with the avalanchego JSON-RPC APIs.
+// It fails if the endpoint is unreachable or of another network, and
+// otherwise reports the error of each chain with its balance.
+func fetchBalances(ctx context.Context, endpoint string, ki keyinfo.Info, networkID uint32) ([]chainBalance, error) {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	var info struct {
+		NetworkID string `json:"networkID"`
+	}
+	if err := callAPI(ctx, endpoint+"/ext/info", "info.getNetworkID", struct{}{}, &info); err != nil {
+		return nil, err
+	}
+	if info.NetworkID != strconv.FormatUint(uint64(networkID), 10) {
+		return nil, fmt.Errorf("the endpoint is for network ID %s, not %d", info.NetworkID, networkID)
+	}
+
+	x := chainBalance{chain: "X", address: ki.XAddress, unit: "nAVAX"}
+	var xReply struct {
+		Balance string `json:"balance"`
+	}
+	x.err = callAPI(ctx, endpoint+"/ext/bc/X", "avm.getBalance", map[string]string{"address": ki.XAddress, "assetID": "AVAX"}, &xReply)
+	x.balance = xReply.Balance
+
+	p := chainBalance{chain: "P", address: ki.PAddress, unit: "nAVAX"}
+	var pReply struct {
+		Balance string `json:"balance"`
+	}
+	p.err = callAPI(ctx, endpoint+"/ext/bc/P", "platform.getBalance", map[string][]string{"addresses": {ki.PAddress}}, &pReply)
+	p.balance = pReply.Balance
+
+	// the C-chain AVAX is held by the eth address, the C-chain bech32
+	// address only holds the atomic UTXOs in flight
+	c := chainBalance{chain: "C", address: ki.EthAddress, unit: "wei"}
+	var cReply string
+	if c.err = callAPI(ctx, endpoint+"/ext/bc/C/rpc", "eth_getBalance", []string{ki.EthAddress, "latest"}, &cReply); c.err == nil {
+		wei, ok := new(big.Int).SetString(strings.TrimPrefix(cReply, "0x"), 16)
+		if !ok {
+			c.err = fmt.Errorf("invalid eth_getBalance result %q", cReply)
+		} else {
+			c.balance = wei.String()
+		}
+	}
+	return []chainBalance{x, p, c}, nil
+}
+
+// callAPI calls the JSON-RPC 2.0 method of the API, and decodes its result.
+func callAPI(ctx context.Context, url string, method string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxAPIResponseSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", method, resp.Status)
+	}
+	var reply struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(b, &reply); err != nil {
+		return fmt.Errorf("invalid %s response (%v)", method, err)
+	}
+	if reply.Error != nil {
+		return fmt.Errorf("%s failed (%s, code %d)", method, reply.Error.Message, reply.Error.Code)
+	}
+	if len(reply.Result) == 0 {
+		return errors.New(method + " returned no result")
+	}
+	return json.Unmarshal(reply.Result, result)
+}
@@ -0,0 +1,71 @@
+//go:build aws
+// +build aws
+
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// go run -tags aws . 9999 --key-source ssm:///avalanche/keys/ewoq
+// go run -tags aws . 9999 --key-source secretsmanager://arn:aws:secretsmanager:us-west-2:123456789012:secret:ewoq
+func init() {
+	keySourceFetchers["ssm"] = fetchSSMParameter
+	keySourceFetchers["secretsmanager"] = fetchSecretsManagerSecret
+}
+
+// newAWSSession returns the session with the standard AWS credential chain
+// and region resolution (e.g., $AWS_PROFILE, $AWS_REGION, ~/.aws/config).
+func newAWSSession() (*session.Session, error) {
+	return session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+}
+
+// fetchSSMParameter fetches the key file contents from the SSM parameter,
+// decrypting it if it is a SecureString.
+func fetchSSMParameter(ctx context.Context, name string) ([]byte, error) {
+	sess, err := newAWSSession()
+	if err != nil {
+		return nil, err
+	}
+	out, err := ssm.New(sess).GetParameterWithContext(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.Parameter == nil || out.Parameter.Value == nil {
+		return nil, errors.New("the SSM parameter has no value")
+	}
+	return []byte(*out.Parameter.Value), nil
+}
+
+// fetchSecretsManagerSecret fetches the key file contents from the current
+// version of the Secrets Manager secret, stored either as a string or binary.
+func fetchSecretsManagerSecret(ctx context.Context, id string) ([]byte, error) {
+	sess, err := newAWSSession()
+	if err != nil {
+		return nil, err
+	}
+	out, err := secretsmanager.New(sess).GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(id),
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case out.SecretString != nil:
+		return []byte(*out.SecretString), nil
+	case out.SecretBinary != nil:
+		return out.SecretBinary, nil
+	}
+	return nil, errors.New("the secret has no value")
+}
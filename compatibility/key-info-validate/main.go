@@ -2,153 +2,5290 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
 	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
-	"reflect"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"text/tabwriter"
+	"time"
+	"unicode"
 
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/staking"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/crypto"
 	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ethereum/go-ethereum/common"
 	eth_crypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/fsnotify/fsnotify"
+	"github.com/gyuho/avalanche-ops/compatibility/pkg/keyinfo"
+	"github.com/skip2/go-qrcode"
 	"sigs.k8s.io/yaml"
 )
 
-var keyFactory = new(crypto.FactorySECP256K1R)
+// go run main.go ../../artifacts/ewoq.key.json 9999
+// cat ../../artifacts/ewoq.key.json | go run main.go - 9999
+// go run main.go validate a.key.json b.key.json c.key.json 9999
+// go run main.go generate 9999 /tmp/test.key.json
+// go run main.go generate-staker /tmp/node1 9999
+// go run main.go from-mnemonic "[MNEMONIC]" 0 9999
+// go run main.go from-xpub [XPUB] 0 9999
+// go run main.go validate-dir /tmp/keys 9999
+// go run main.go validate-array /tmp/keys.json 9999
+// go run main.go verify-address ../../artifacts/ewoq.key.json X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p
+// go run main.go match-addresses /tmp/keys /tmp/addresses.txt
+// go run main.go sign ../../artifacts/ewoq.key.json 9999 "hello world"
+// go run main.go verify-signature X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p "hello world" [SIGNATURE]
+// go run main.go eth-sign ../../artifacts/ewoq.key.json "hello world"
+// go run main.go eth-verify 0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC "hello world" [SIGNATURE]
+// go run main.go export-keystore ../../artifacts/ewoq.key.json /tmp/ewoq.keystore.json --passphrase-file /tmp/passphrase
+// go run main.go import-keystore /tmp/ewoq.keystore.json 9999 --passphrase-file /tmp/passphrase
+// go run main.go encrypt-file ../../artifacts/ewoq.key.json /tmp/ewoq.encrypted.json --passphrase-file /tmp/passphrase
+// go run main.go export-pem ../../artifacts/ewoq.key.json /tmp/ewoq.pem
+// go run main.go import-pem /tmp/ewoq.pem 9999
+// go run main.go export-eth-key ../../artifacts/ewoq.key.json --i-understand-this-exposes-my-key
+// go run main.go vanity 9999 abc /tmp/vanity.key.json
+// go run main.go ewoq 12345
+// go run main.go explain ../../artifacts/ewoq.key.json 9999
+// go run main.go explain-c ../../artifacts/ewoq.key.json 9999
+// go run main.go identify X-avax18jma8ppw3nhx5r4ap8clazz0dps7rv5ukulre5
+// go run main.go short-to-nodeid 7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg
+// go run main.go nodeid-to-short NodeID-7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg
+// go run main.go owner-id ../../artifacts/ewoq.key.json
+// go run main.go diff ../../artifacts/ewoq.key.json /tmp/ewoq.hex.key 9999 --b-key-format hex
+// go run main.go refresh /tmp/old.key.json 9999
+// go run main.go reward-address ../../artifacts/ewoq.key.json 9999 --reward-key /tmp/reward.key.json
+// go run main.go doctor
+// go run main.go ../../artifacts/ewoq.key.json 9999 --log-level debug
+// go run main.go /tmp/fork.key.json 9999 --key-prefix SecretKey-
+// go run main.go /tmp/ewoq.encrypted.json 9999 --key-passphrase-file /tmp/passphrase
+// go run main.go /tmp/partial.key.json 9999 --skip-schema
+// go run main.go --redact-log export-eth-key ../../artifacts/ewoq.key.json --i-understand-this-exposes-my-key
+// go run main.go --offline generate 1 /tmp/mainnet.key.json
+func main() {
+	err := run(os.Args[1:])
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	}
+	// the redacted output is flushed before exiting
+	stopRedacting()
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		os.Exit(exitCode(err))
+	}
+}
+
+func run(args []string) error {
+	args, err := extractGlobalFlags(args)
+	if err != nil {
+		return err
+	}
+	// the flags of the mode are not parsed yet, so --show-secret is looked up
+	// in the args to decide whether to redact before anything is printed
+	if err := applyGlobalFlags(hasFlag(args, "show-secret")); err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		if mode, ok := modes[args[0]]; ok {
+			return mode(args[1:])
+		}
+	}
+	return validate(args)
+}
+
+// modes maps the mode names to the modes, which take the args after the mode name.
+// The default mode (no mode name) is validate.
+var modes = map[string]func(args []string) error{
+	"validate":         validate,
+	"generate":         generate,
+	"generate-staker":  generateStaker,
+	"from-mnemonic":    fromMnemonic,
+	"from-xpub":        fromXpub,
+	"validate-dir":     validateDir,
+	"validate-array":   validateArray,
+	"verify-address":   verifyAddress,
+	"match-addresses":  matchAddresses,
+	"sign":             sign,
+	"verify-signature": verifySignature,
+	"eth-sign":         ethSign,
+	"eth-verify":       ethVerify,
+	"export-keystore":  exportKeystore,
+	"import-keystore":  importKeystore,
+	"encrypt-file":     encryptFile,
+	"export-pem":       exportPEM,
+	"import-pem":       importPEM,
+	"export-eth-key":   exportEthKey,
+	"vanity":           vanity,
+	"ewoq":             ewoq,
+	"explain":          explain,
+	"explain-c":        explainC,
+	"identify":         identify,
+	"short-to-nodeid":  shortToNodeID,
+	"nodeid-to-short":  nodeIDToShort,
+	"owner-id":         ownerID,
+	"diff":             diffKeys,
+	"refresh":          refresh,
+	"reward-address":   rewardAddress,
+	"doctor":           doctor,
+}
+
+// logLevel is the minimum level of the diagnostic messages logged to stderr.
+// The results are always printed to stdout, so they can be piped.
+type logLevel int
+
+const (
+	logLevelDebug logLevel = iota
+	logLevelInfo
+	logLevelWarn
+	logLevelError
+)
+
+var logLevelNames = []string{"debug", "info", "warn", "error"}
+
+// leveledLogger logs the diagnostic messages at or above its level.
+type leveledLogger struct {
+	level logLevel
+	std   *log.Logger
+}
+
+var logger = &leveledLogger{level: logLevelInfo, std: log.New(os.Stderr, "", log.LstdFlags)}
+
+func (l *leveledLogger) logf(level logLevel, format string, a ...interface{}) {
+	if level < l.level {
+		return
+	}
+	l.std.Printf(strings.ToUpper(logLevelNames[level])+": "+format, a...)
+}
+
+func (l *leveledLogger) Debugf(format string, a ...interface{}) { l.logf(logLevelDebug, format, a...) }
+func (l *leveledLogger) Infof(format string, a ...interface{})  { l.logf(logLevelInfo, format, a...) }
+func (l *leveledLogger) Warnf(format string, a ...interface{})  { l.logf(logLevelWarn, format, a...) }
+func (l *leveledLogger) Errorf(format string, a ...interface{}) { l.logf(logLevelError, format, a...) }
+
+// applyLogLevel sets the logger level from the "--log-level [LEVEL]" values.
+func applyLogLevel(values []string) error {
+	for _, value := range values {
+		found := false
+		for level, levelName := range logLevelNames {
+			if value == levelName {
+				logger.level, found = logLevel(level), true
+			}
+		}
+		if !found {
+			return usageError("unknown --log-level %q (expected %s)", value, strings.Join(logLevelNames, ", "))
+		}
+	}
+	return nil
+}
+
+// applyKeyPrefix sets the CB58 private key prefix from the "--key-prefix [PREFIX]" values.
+// The same prefix is used to decode and encode within the run, so a key
+// with another prefix fails instead of being silently re-prefixed.
+func applyKeyPrefix(values []string) error {
+	for _, value := range values {
+		if value != values[0] {
+			return usageError("conflicting --key-prefix %q and %q (one prefix per run)", values[0], value)
+		}
+	}
+	if len(values) == 0 || values[0] == keyinfo.PrivateKeyPrefix() {
+		return nil
+	}
+	if err := keyinfo.SetPrivateKeyPrefix(values[0]); err != nil {
+		return usageError("%v", err)
+	}
+	if values[0] != keyinfo.DefaultPrivateKeyPrefix {
+		logger.Warnf("using the non-standard private key prefix %q (avalanchego uses %q)", values[0], keyinfo.DefaultPrivateKeyPrefix)
+	}
+	return nil
+}
+
+// stopRedacting flushes the redacted stdout and stderr (see startRedacting),
+// and restores the original ones.
+var stopRedacting = func() {}
+
+// startRedacting redacts everything printed to stdout and stderr from now on
+// (see the "--redact-log" flag). Anything that looks like a private key is
+// redacted (see keyinfo.RedactSecrets) whichever mode prints it, including the
+// modes that print the keys on purpose (e.g., export-eth-key), unless
+// --show-secret is set. The key files are written as is.
+func startRedacting() error {
+	if redacting {
+		return nil
+	}
+	stopStdout, err := redactFile(&os.Stdout)
+	if err != nil {
+		return ioError(err)
+	}
+	stopStderr, err := redactFile(&os.Stderr)
+	if err != nil {
+		stopStdout()
+		return ioError(err)
+	}
+	logger.std.SetOutput(os.Stderr)
+	redacting = true
+	stopRedacting = func() {
+		stopStdout()
+		stopStderr()
+		logger.std.SetOutput(os.Stderr)
+	}
+	return nil
+}
+
+// redacting is true once startRedacting replaced stdout and stderr.
+var redacting bool
+
+// redactFile replaces the file (os.Stdout or os.Stderr) with a pipe,
+// whose contents are redacted to the original file until stopped.
+func redactFile(f **os.File) (func(), error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	orig := *f
+	*f = w
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rw := keyinfo.NewRedactingWriter(orig)
+		io.Copy(rw, r)
+		rw.Flush()
+	}()
+	return func() {
+		w.Close()
+		<-done
+		*f = orig
+	}, nil
+}
+
+// offline is the "--offline" flag, which applies to all modes, for the key
+// generation on an airgapped machine. With it, nothing leaves the machine:
+//   - every HTTP request of the default transport fails (see offlineTransport),
+//     which covers the AWS SDK of the "-tags aws" key sources;
+//   - --key-source ssm:// and secretsmanager:// fail before anything is
+//     fetched, and only env: is allowed;
+//   - --check-balance fails, before querying the --endpoint;
+//   - --compare-tool fails, since the other tool is not known to stay offline.
+//
+// Reading and writing the local files (including stdin, the passphrase files,
+// and the --watch file events), the environment variables, and the
+// randomness of the OS are not network access, and are allowed.
+var offline bool
+
+// errOffline is the error of any network access with --offline.
+var errOffline = errors.New("network access is disabled by --offline")
+
+// offlineTransport is the HTTP transport that fails every request, installed
+// as the default transport with --offline, so any code path that attempts
+// a network call fails loudly instead of reaching the network.
+type offlineTransport struct{}
+
+func (offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("%s %s (%w)", req.Method, req.URL.Redacted(), errOffline)
+}
+
+// setOffline disables the network access (see the "--offline" flag).
+func setOffline() {
+	if offline {
+		return
+	}
+	offline = true
+	http.DefaultTransport = offlineTransport{}
+	http.DefaultClient.Transport = offlineTransport{}
+	logger.Debugf("network access is disabled by --offline")
+}
+
+// checkOnline fails with a usage error if the feature may access the network
+// and it is disabled by --offline.
+func checkOnline(feature string) error {
+	if offline {
+		return usageError("%s may access the network (%v)", feature, errOffline)
+	}
+	return nil
+}
+
+// globalFlag is a flag that applies to all modes. It is taken before the
+// mode name (see extractGlobalFlags), or with the flags of the mode, since it
+// is defined on the flag set of every mode (see parseFlags).
+type globalFlag struct {
+	name  string
+	usage string
+	// isBool is true for the flags without a value (e.g., --offline)
+	isBool bool
+	// values are all the values of the flag in the args, in order
+	values []string
+}
+
+func (f *globalFlag) String() string {
+	if len(f.values) == 0 {
+		return ""
+	}
+	return f.values[len(f.values)-1]
+}
+
+func (f *globalFlag) Set(value string) error {
+	if f.isBool {
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("invalid --%s %q (expected true or false)", f.name, value)
+		}
+	}
+	f.values = append(f.values, value)
+	return nil
+}
+
+func (f *globalFlag) IsBoolFlag() bool { return f.isBool }
+
+// enabled returns the last value of the bool flag.
+func (f *globalFlag) enabled() bool {
+	v, _ := strconv.ParseBool(f.String())
+	return v
+}
+
+var (
+	logLevelFlag          = &globalFlag{name: "log-level", usage: "minimum level of the messages logged to stderr (" + strings.Join(logLevelNames, ", ") + ")"}
+	keyPrefixFlag         = &globalFlag{name: "key-prefix", usage: "CB58 private key prefix (default " + keyinfo.DefaultPrivateKeyPrefix + ")"}
+	keyPassphraseFileFlag = &globalFlag{name: "key-passphrase-file", usage: "file to read the passphrase of the encrypted key files from"}
+	redactLogFlag         = &globalFlag{name: "redact-log", usage: "redact the private keys printed to stdout and stderr", isBool: true}
+	offlineFlag           = &globalFlag{name: "offline", usage: "disable the network access", isBool: true}
+	skipSchemaFlag        = &globalFlag{name: "skip-schema", usage: "skip the JSON schema check of the key files", isBool: true}
+
+	globalFlags = []*globalFlag{logLevelFlag, keyPrefixFlag, keyPassphraseFileFlag, redactLogFlag, offlineFlag, skipSchemaFlag}
+)
+
+// extractGlobalFlags removes the global flags before the mode name and right
+// after it, and returns the rest of the args. It stops at "--" and at the
+// first other argument, which is left to the flags of the mode (see parseFlags),
+// so a positional value (e.g., the message "--offline" of sign) is never taken
+// as a global flag.
+func extractGlobalFlags(args []string) ([]string, error) {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if _, ok := modes[arg]; ok && len(rest) == 0 {
+			rest = append(rest, arg)
+			continue
+		}
+		name, value, hasValue := splitFlag(arg)
+		var f *globalFlag
+		for _, gf := range globalFlags {
+			if gf.name == name {
+				f = gf
+			}
+		}
+		if f == nil {
+			return append(rest, args[i:]...), nil
+		}
+		if !hasValue {
+			value = "true"
+			if !f.isBool {
+				if i+1 == len(args) {
+					return nil, usageError("flag needs an argument: -%s", name)
+				}
+				i++
+				value = args[i]
+			}
+		}
+		if err := f.Set(value); err != nil {
+			return nil, usageError("%v", err)
+		}
+	}
+	return rest, nil
+}
+
+// splitFlag splits the "-[NAME]", "--[NAME]", and "--[NAME]=[VALUE]" arg, as the
+// flag package does. The name is empty if the arg is not a flag (including "-" and "--").
+func splitFlag(arg string) (name string, value string, hasValue bool) {
+	if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+		return "", "", false
+	}
+	name = strings.TrimPrefix(arg[1:], "-")
+	if idx := strings.Index(name, "="); idx >= 0 {
+		return name[:idx], name[idx+1:], true
+	}
+	return name, "", false
+}
+
+// hasFlag returns true if the bool flag is set in the args before "--".
+func hasFlag(args []string, name string) bool {
+	set := false
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		n, value, hasValue := splitFlag(arg)
+		if n != name {
+			continue
+		}
+		set = true
+		if hasValue {
+			set, _ = strconv.ParseBool(value)
+		}
+	}
+	return set
+}
+
+// applyGlobalFlags applies the global flags (see globalFlags) taken so far:
+// once the flags before the mode name are extracted, and again once the
+// flags of the mode are parsed, which may set more of them.
+func applyGlobalFlags(showSecret bool) error {
+	if err := applyLogLevel(logLevelFlag.values); err != nil {
+		return err
+	}
+	if err := applyKeyPrefix(keyPrefixFlag.values); err != nil {
+		return err
+	}
+	if len(keyPassphraseFileFlag.values) > 0 {
+		keyPassphraseFile = keyPassphraseFileFlag.String()
+	}
+	if redactLogFlag.enabled() && !showSecret {
+		if err := startRedacting(); err != nil {
+			return err
+		}
+	}
+	if offlineFlag.enabled() {
+		setOffline()
+	}
+	skipSchema = skipSchemaFlag.enabled()
+	return nil
+}
+
+const (
+	exitCodeValidation = 1
+	exitCodeUsage      = 2
+	exitCodeIO         = 3
+	exitCodeTimeout    = 4
+	// exitCodeWrongNetwork is for the key files that are valid, but for another
+	// network than the requested one, to recompute instead of alerting.
+	exitCodeWrongNetwork = 5
+)
+
+// exitError is an error with the exit code that the process should return.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// usageError is returned for wrong arguments or flags.
+func usageError(format string, a ...interface{}) error {
+	return &exitError{code: exitCodeUsage, err: fmt.Errorf(format, a...)}
+}
+
+// ioError is returned when reading or writing files fails.
+func ioError(err error) error {
+	return &exitError{code: exitCodeIO, err: err}
+}
+
+// timeoutError is returned when --timeout cuts the run short,
+// after reporting the partial results.
+func timeoutError(format string, a ...interface{}) error {
+	return &exitError{code: exitCodeTimeout, err: fmt.Errorf(format, a...)}
+}
+
+// exitCode returns the exit code for the error,
+// defaulting to the validation failure code.
+func exitCode(err error) int {
+	var ee *exitError
+	if errors.As(err, &ee) {
+		return ee.code
+	}
+	return exitCodeValidation
+}
+
+// go run main.go ../../artifacts/ewoq.key.json 9999
+// go run main.go /tmp/network-id.key.json
+// go run main.go ../../artifacts/ewoq.key.json 9999 --format json
+// go run main.go ../../artifacts/ewoq.key.json 5 --funding-help
+// go run main.go /tmp/ewoq.base64.key 9999 --key-format base64
+// go run main.go ../../artifacts/ewoq.key.json 9999 --format json --json-compact | jq .
+// go run main.go ../../artifacts/ewoq.key.json 9999 --format csv --no-header
+// go run main.go ../../artifacts/ewoq.key.json 9999 --genesis-alloc --balance 1000000000
+// go run main.go /tmp/test.hex.key 9999 --key-format hex
+// cat ../../artifacts/ewoq.key.json | go run main.go - 9999
+// go run main.go ../../artifacts/ewoq.key.json 9999 --quiet
+// go run main.go ../../artifacts/ewoq.key.json 9999 --show-secret
+// go run main.go ../../artifacts/ewoq.key.json 9999 --verbose
+// go run main.go ../../artifacts/ewoq.key.json 9999 --strict
+// go run main.go /tmp/old.key.json 9999 --fields x,eth
+// go run main.go /tmp/test.key.json 9999 --watch
+// go run main.go ../../artifacts/ewoq.key.json 9999 --format csv --address-style hash-hex
+// eval "$(go run main.go ../../artifacts/ewoq.key.json 9999 --format env --env-prefix EWOQ_)"
+// go run main.go ../../artifacts/ewoq.key.json 9999 --format hcl --hcl-variable ewoq_key > /tmp/ewoq.auto.tfvars
+// go run main.go ../../artifacts/ewoq.key.json 9999 --quiet --assert-x X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p
+// go run main.go /tmp/fuji.key.json --network fuji
+// go run main.go /tmp/test.key.json 9999 --hrp mynet
+// go run main.go /tmp/old.key.json 9999 --out /tmp/new.key.json
+// go run main.go /tmp/old.key.json 9999 --migrate
+// go run main.go /tmp/old.key.yaml 9999 --canonicalize --out /tmp/canonical.key.json
+// go run main.go ../../artifacts/ewoq.key.json 9999 --qr --qr-chain P --qr-out /tmp/ewoq.png
+// go run -tags aws . 9999 --key-source ssm:///avalanche/keys/ewoq
+// go run -tags aws . 9999 --key-source secretsmanager://arn:aws:secretsmanager:us-west-2:123456789012:secret:ewoq
+// AVALANCHE_PRIVATE_KEY=PrivateKey-... go run main.go 9999 --key-source env:AVALANCHE_PRIVATE_KEY --key-format subnet-cli
+// go run main.go ../../artifacts/ewoq.key.json 9999 --encoder x,p,c,eth
+// go run main.go /tmp/no-checksum.key.json 9999 --no-checksum
+// go run main.go ../../artifacts/ewoq.key.json 9999 --labels "p=validator reward,c=faucet funding"
+// go run -tags balance . ../../artifacts/ewoq.key.json 9999 --check-balance --endpoint http://127.0.0.1:9650
+// go run main.go ../../artifacts/ewoq.key.json 9999 --only x
+// go run main.go ../../artifacts/ewoq.key.json 9999 --compare-tool "cargo run -q --example avalanche_key_info_validate --"
+func validate(args []string) error {
+	rawArgs := args
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	format := fs.String("format", "text", "output format (text, json, csv, env, hcl)")
+	envPrefix := fs.String("env-prefix", "", "prefix of the \"--format env\" variable names (e.g., OPERATOR_ for OPERATOR_X_ADDRESS)")
+	hclVariable := fs.String("hcl-variable", defaultHCLVariable, "name of the \"--format hcl\" Terraform variable (map of the addresses, e.g., in a .tfvars file)")
+	noHeader := noHeaderFlag(fs)
+	jsonCompact := fs.Bool("json-compact", false, "print the JSON output (--format json, --genesis-alloc) on a single line instead of indented")
+	genesisAlloc := fs.Bool("genesis-alloc", false, "print the avalanchego genesis allocations that fund the key, instead of the key info")
+	balance := fs.Uint64("balance", keyinfo.DefaultGenesisBalance, "--genesis-alloc balance in nAVAX for each of the X, P, and C-chain addresses")
+	quiet := fs.Bool("quiet", false, "only print the final result and errors (e.g., to keep keys out of CI logs)")
+	showSecret := fs.Bool("show-secret", false, "print the private keys in full instead of redacted")
+	verbose := fs.Bool("verbose", false, "print each intermediate encoding step, with the text format (ignored with --quiet)")
+	hrpOverride := hrpFlag(fs)
+	keyFormat := fs.String("key-format", keyFormatKeyInfo, "input key file format (keyinfo, hex, subnet-cli, base64)")
+	chains := chainsFlag(fs)
+	networks := networksFlag(fs)
+	includePubkey := includePubkeyFlag(fs)
+	out := fs.String("out", "", "file to write the key info regenerated from the private key to, in the same format as the input")
+	force := fs.Bool("force", false, "overwrite the --out file if it already exists")
+	migrate := fs.Bool("migrate", false, "rewrite the key file in place with the complete set of fields")
+	canonicalize := fs.Bool("canonicalize", false, "write the canonical form of the key file (re-derived fields in a fixed order, as JSON) to --out or stdout, instead of validating")
+	qr := registerQRFlags(fs)
+	asserts := registerAssertFlags(fs)
+	watch := fs.Bool("watch", false, "revalidate the key file on every change until interrupted (Ctrl-C)")
+	addressStyle := fs.String("address-style", addressStyleFull, "chain address output style (full, bech32-only, hash-hex), validation always uses the full addresses")
+	fundingHelp := fundingHelpFlag(fs)
+	strictNetwork := strictNetworkFlag(fs)
+	strict := strictFlag(fs)
+	fields := fieldsFlag(fs)
+	networkName := networkFlag(fs)
+	keySource := fs.String("key-source", "", "fetch the key file from a secret store instead of [KEY-PATH] (env:[VAR], or ssm://[NAME] and secretsmanager://[ARN] with -tags aws)")
+	noChecksum := fs.Bool("no-checksum", false, "accept the private_key and short_address in CB58 without the checksum, and also print them without it (NON-STANDARD, for the tools that do their own checksumming)")
+	encoder := fs.String("encoder", "", "comma-separated address encoders to also encode the key's address with ("+keyinfo.AddressEncoderNames()+", or a registered custom encoder)")
+	labels := fs.String("labels", "", "comma-separated [CHAIN]=[LABEL] pairs to print next to the addresses, for x, p, c, eth, and short (e.g., \"p=validator reward,c=faucet funding\")")
+	only := fs.String("only", "", "print only the address of the chain (x, p, c, eth, or short) and nothing else, for scripts")
+	checkBalance := fs.Bool("check-balance", false, "also fetch and print the X, P, and C-chain balances of the key from --endpoint (with -tags balance), without failing the validation if the lookup fails")
+	endpoint := fs.String("endpoint", defaultEndpoint, "avalanchego API endpoint for --check-balance")
+	compareTool := fs.String("compare-tool", "", "command of another key tool to run with [KEY-PATH] [NETWORK-ID], failing if any address it prints differs (e.g., \"avalanche-key-tool validate\")")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if *keySource != "" {
+		if len(args) > 1 {
+			return usageError("expected 0 or 1 args with --key-source: [NETWORK-ID], got %q", args)
+		}
+		if stdinBytes, err = fetchKeySource(*keySource); err != nil {
+			return err
+		}
+		// the fetched key is read as stdin, so it is never written to disk
+		args = append([]string{stdinPath}, args...)
+	}
+	onlyField, err := parseOnly(*only)
+	if err != nil {
+		return err
+	}
+	if onlyField != "" {
+		if *canonicalize || *checkBalance || *labels != "" || *encoder != "" {
+			return usageError("--only cannot be used with --canonicalize, --check-balance, --labels, or --encoder")
+		}
+		// nothing but the address is printed, same as nothing but the result with --quiet
+		*quiet = true
+	}
+	if *quiet && logger.level < logLevelError {
+		logger.level = logLevelError
+	}
+	if err := parseFields(*fields); err != nil {
+		return err
+	}
+	if *format != "text" && *format != "json" && *format != "csv" && *format != "env" && *format != "hcl" {
+		return usageError("unknown --format %q (expected text, json, csv, env, or hcl)", *format)
+	}
+	if *envPrefix != "" && !envNamePattern.MatchString(*envPrefix) {
+		return usageError("invalid --env-prefix %q (expected letters, digits, and underscores, not starting with a digit)", *envPrefix)
+	}
+	if !hclNamePattern.MatchString(*hclVariable) {
+		return usageError("invalid --hcl-variable %q (expected letters, digits, underscores, and dashes, starting with a letter or an underscore)", *hclVariable)
+	}
+	if *genesisAlloc && *format != "text" {
+		return usageError("--genesis-alloc cannot be used with --format %s", *format)
+	}
+	encoders, err := parseEncoders(*encoder)
+	if err != nil {
+		return err
+	}
+	if *noChecksum && (*canonicalize || *out != "" || *migrate) {
+		return usageError("--no-checksum cannot be used with --canonicalize, --out, or --migrate")
+	}
+	addrLabels, err := parseLabels(*labels)
+	if err != nil {
+		return err
+	}
+	if *compareTool != "" {
+		if err := checkOnline("--compare-tool"); err != nil {
+			return err
+		}
+	}
+	if *endpoint != defaultEndpoint && !*checkBalance {
+		return usageError("--endpoint requires --check-balance")
+	}
+	if *checkBalance {
+		if *quiet {
+			return usageError("--check-balance cannot be used with --quiet")
+		}
+		if err := checkOnline("--check-balance"); err != nil {
+			return err
+		}
+		if balanceFetcher == nil {
+			return usageError("--check-balance is not built in, rebuild with \"-tags balance\"")
+		}
+	}
+	switch *addressStyle {
+	case addressStyleFull, addressStyleBech32Only, addressStyleHashHex:
+	default:
+		return usageError("unknown --address-style %q (expected full, bech32-only, or hash-hex)", *addressStyle)
+	}
+	switch *keyFormat {
+	case keyFormatKeyInfo, keyFormatHex, keyFormatSubnetCLI, keyFormatBase64:
+	default:
+		return usageError("unknown --key-format %q (expected keyinfo, hex, subnet-cli, or base64)", *keyFormat)
+	}
+	if fpaths, networkArg, ok := multipleKeyFiles(args, *networkName); ok {
+		var conflicting []string
+		fs.Visit(func(f *flag.Flag) {
+			if !multipleKeyFilesFlags[f.Name] {
+				conflicting = append(conflicting, "--"+f.Name)
+			}
+		})
+		if len(conflicting) > 0 {
+			return usageError("%s cannot be used with multiple key files", strings.Join(conflicting, ", "))
+		}
+		if *format != "text" && *format != "csv" {
+			return usageError("unknown --format %q with multiple key files (expected text or csv)", *format)
+		}
+		return validateFiles(fpaths, networkArg, *networkName, *format, *noHeader, *keyFormat, *hrpOverride, *strictNetwork, *strict)
+	}
+	renderFormat := *format
+	if *genesisAlloc {
+		renderFormat = formatGenesisAlloc
+	}
+	if err := checkFormatFlags(fs, renderFormat); err != nil {
+		return err
+	}
+	if len(args) != 1 && len(args) != 2 {
+		return usageError("expected 1 or 2 args: [KEY-PATH] [NETWORK-ID], got %q", args)
+	}
+	if err := qr.check(); err != nil {
+		return err
+	}
+	if *watch {
+		if args[0] == stdinPath {
+			return usageError("--watch requires a key file path")
+		}
+		if *migrate || *out != "" || *canonicalize {
+			return usageError("--watch cannot be used with --migrate, --out, or --canonicalize")
+		}
+		return watchKeyFile(args[0], func() error {
+			return validate(withoutFlag(rawArgs, "watch"))
+		})
+	}
+	if *migrate {
+		if *out != "" {
+			return usageError("--migrate cannot be used with --out")
+		}
+		if args[0] == stdinPath || *keyFormat != keyFormatKeyInfo {
+			return usageError("--migrate requires a key info file path")
+		}
+		*out, *force = args[0], true
+	}
+	if *compareTool != "" && args[0] == stdinPath {
+		return usageError("--compare-tool requires a key file path")
+	}
+	aliases, err := parseChains(*chains)
+	if err != nil {
+		return err
+	}
+	networkIDs, err := parseNetworks(*networks)
+	if err != nil {
+		return err
+	}
+
+	networkID, err := resolveFileNetworkID(args, *networkName, *keyFormat)
+	if err != nil {
+		return err
+	}
+	if err := checkNetworkID(networkID, *strictNetwork); err != nil {
+		return err
+	}
+	hrp, err := resolveHRP(networkID, *hrpOverride)
+	if err != nil {
+		return err
+	}
+	warnNetworkHRP(networkID, hrp)
+	if *canonicalize {
+		return canonicalizeKeyFile(args[0], *out, *force, *keyFormat, networkID, hrp)
+	}
+	if *out != "" {
+		if err := rewriteKeyFile(args[0], *out, *force, *keyFormat, networkID, hrp, aliases, networkIDs); err != nil {
+			return err
+		}
+		// validate the written file
+		args[0], *keyFormat = *out, keyFormatKeyInfo
+	}
+
+	opts := validateOptions{
+		fpath:         args[0],
+		keyFormat:     *keyFormat,
+		networkID:     networkID,
+		hrp:           hrp,
+		strict:        *strict,
+		noChecksum:    *noChecksum,
+		asserts:       asserts,
+		aliases:       aliases,
+		networkIDs:    networkIDs,
+		includePubkey: *includePubkey,
+		encoders:      encoders,
+		compareTool:   *compareTool,
+		format:        renderFormat,
+		quiet:         *quiet,
+		showSecret:    *showSecret,
+		verbose:       *verbose,
+		addressStyle:  *addressStyle,
+		labels:        addrLabels,
+		jsonCompact:   *jsonCompact,
+		balance:       *balance,
+		noHeader:      *noHeader,
+		envPrefix:     *envPrefix,
+		hclVariable:   *hclVariable,
+		qr:            qr,
+		only:          onlyField,
+		checkBalance:  *checkBalance,
+		endpoint:      *endpoint,
+		fundingHelp:   *fundingHelp,
+	}
+	key := decodeAndValidate(opts)
+	if key.err == nil {
+		key.err = crossCheck(key, opts)
+	}
+	return validateRenderers[opts.format](key, opts)
+}
+
+// validateOptions are the validate flags for a single key file, shared by
+// its stages: decodeAndValidate, crossCheck, and the renderer of the format.
+type validateOptions struct {
+	fpath         string
+	keyFormat     string
+	networkID     uint32
+	hrp           string
+	strict        bool
+	noChecksum    bool
+	asserts       assertFlags
+	aliases       []string
+	networkIDs    []uint32
+	includePubkey bool
+	encoders      []string
+	compareTool   string
+
+	// format is the renderer (see validateRenderers)
+	format       string
+	quiet        bool
+	showSecret   bool
+	verbose      bool
+	addressStyle string
+	labels       map[string]string
+	jsonCompact  bool
+	balance      uint64
+	noHeader     bool
+	envPrefix    string
+	hclVariable  string
+	qr           qrFlags
+	only         string
+	checkBalance bool
+	endpoint     string
+	fundingHelp  bool
+}
+
+// validatedKey is the key file decoded and validated by decodeAndValidate.
+type validatedKey struct {
+	// raw is the key file as read
+	raw []byte
+	// isDecoded is true once raw is decoded to decoded, before the
+	// --no-checksum encodings are resolved
+	isDecoded bool
+	decoded   keyinfo.Info
+	// ki is the key info to validate, with the --chains, --networks, and
+	// --include-pubkey fields once it is valid
+	ki keyinfo.Info
+	// encoded maps each --encoder name to the encoded address
+	encoded map[string]string
+	// err is the first error of the decoding, the validation, or a cross-check
+	err error
+}
+
+// decodeAndValidate reads, decodes, and validates the key file, and derives
+// the optional fields. It stops at the first error, which is returned in the
+// validated key for the renderer to report.
+func decodeAndValidate(opts validateOptions) (key validatedKey) {
+	if key.raw, key.err = readKeyFile(opts.fpath); key.err != nil {
+		return key
+	}
+	logger.Debugf("decoding the %s key", opts.keyFormat)
+	if key.decoded, key.err = decodeKey(key.raw, opts.keyFormat, opts.networkID, opts.hrp); key.err != nil {
+		return key
+	}
+	key.isDecoded = true
+	ki := key.decoded
+	if opts.noChecksum {
+		if ki, key.err = keyinfo.FromNoChecksum(ki); key.err != nil {
+			return key
+		}
+	}
+	key.ki = ki
+	if key.err = validateKey(key.ki, opts.networkID, opts.hrp, opts.strict); key.err != nil {
+		return key
+	}
+	if key.err = opts.asserts.check(key.ki); key.err != nil {
+		return key
+	}
+	if key.err = addAddresses(&key.ki, opts.aliases, opts.networkIDs, opts.hrp); key.err != nil {
+		return key
+	}
+	if key.err = addPublicKeys(&key.ki, opts.includePubkey); key.err != nil {
+		return key
+	}
+	key.encoded, key.err = encodeAddresses(key.ki, opts.encoders, opts.networkID)
+	return key
+}
+
+// crossCheck cross-checks the valid key with the other tools (--compare-tool).
+// The comparison goes to stdout with the text format, and to stderr with the
+// others, so their output stays parseable.
+func crossCheck(key validatedKey, opts validateOptions) error {
+	if opts.compareTool == "" {
+		return nil
+	}
+	w := os.Stdout
+	if opts.format != "text" {
+		w = os.Stderr
+	}
+	return compareWithTool(opts.compareTool, opts.fpath, opts.networkID, key.ki, opts.quiet, w)
+}
+
+// formatGenesisAlloc is the renderer of --genesis-alloc, instead of the text format.
+const formatGenesisAlloc = "genesis-alloc"
+
+// validateRenderers print the validated key in each --format, and return its error.
+var validateRenderers = map[string]func(key validatedKey, opts validateOptions) error{
+	"text":             renderText,
+	"json":             renderJSON,
+	"csv":              renderCSV,
+	"env":              renderEnv,
+	"hcl":              renderHCL,
+	formatGenesisAlloc: renderGenesisAlloc,
+}
+
+// validateFormatFlags are the validate flags that only some of the renderers
+// support, checked up front (see checkFormatFlags), so a flag is never
+// accepted and then ignored by the renderer of another format.
+var validateFormatFlags = map[string][]string{
+	"env-prefix":     {"env"},
+	"hcl-variable":   {"hcl"},
+	"no-header":      {"csv"},
+	"json-compact":   {"json", formatGenesisAlloc},
+	"balance":        {formatGenesisAlloc},
+	"no-checksum":    {"text"},
+	"encoder":        {"text", "json"},
+	"labels":         {"text", "json"},
+	"chains":         {"text", "json"},
+	"networks":       {"text", "json"},
+	"include-pubkey": {"text", "json"},
+	"address-style":  {"text", "json", "csv", "env", "hcl"},
+	"canonicalize":   {"text"},
+	"check-balance":  {"text"},
+	"endpoint":       {"text"},
+	"only":           {"text"},
+	"qr":             {"text"},
+	"qr-chain":       {"text"},
+	"qr-out":         {"text"},
+	"verbose":        {"text"},
+	"funding-help":   {"text"},
+}
+
+// checkFormatFlags fails with a usage error if a flag set in the flag set is
+// not supported by the renderer of the format (see validateFormatFlags).
+func checkFormatFlags(fs *flag.FlagSet, format string) error {
+	describe := func(format string) string {
+		if format == formatGenesisAlloc {
+			return "--" + formatGenesisAlloc
+		}
+		return "--format " + format
+	}
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		formats, ok := validateFormatFlags[f.Name]
+		if !ok || err != nil {
+			return
+		}
+		var supported []string
+		for _, ff := range formats {
+			if ff == format {
+				return
+			}
+			supported = append(supported, describe(ff))
+		}
+		if format == "text" {
+			err = usageError("--%s requires %s", f.Name, strings.Join(supported, " or "))
+			return
+		}
+		err = usageError("--%s cannot be used with %s", f.Name, describe(format))
+	})
+	return err
+}
+
+// renderJSON prints the validation report, including its error, as JSON.
+func renderJSON(key validatedKey, opts validateOptions) error {
+	ki, err := key.ki, key.err
+	rep := validateReport{
+		EncodedAddresses: key.encoded,
+		NetworkID:        opts.networkID,
+		HRP:              opts.hrp,
+		Valid:            err == nil,
+	}
+	if opts.keyFormat == keyFormatKeyInfo && ki.PrivateKey != "" {
+		rep.MissingFields = keyinfo.MissingFields(ki)
+	}
+	if ki.PrivateKey != "" || ki.WatchOnly {
+		if !opts.showSecret {
+			ki = ki.Redacted()
+		}
+		if serr := styleAddresses(&ki, opts.addressStyle); serr != nil && err == nil {
+			err = serr
+		}
+		rep.KeyInfo = &ki
+		rep.Labels = labelAddresses(ki, opts.labels)
+	}
+	if err != nil {
+		rep.Error = err.Error()
+	}
+	b, merr := marshalJSON(rep, opts.jsonCompact)
+	if merr != nil {
+		return merr
+	}
+	fmt.Println(string(b))
+	return err
+}
+
+// renderGenesisAlloc prints the avalanchego genesis allocations that fund the key.
+func renderGenesisAlloc(key validatedKey, opts validateOptions) error {
+	if key.err != nil {
+		return key.err
+	}
+	b, err := marshalJSON(keyinfo.NewGenesisAlloc(key.ki, opts.balance), opts.jsonCompact)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+// renderCSV prints the addresses as a CSV record.
+func renderCSV(key validatedKey, opts validateOptions) error {
+	if key.err != nil {
+		return key.err
+	}
+	ki := key.ki
+	if err := styleAddresses(&ki, opts.addressStyle); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(os.Stdout)
+	if !opts.noHeader {
+		cw.Write(csvHeader)
+	}
+	cw.Write(csvRecord(ki, opts.networkID))
+	cw.Flush()
+	return cw.Error()
+}
+
+// renderEnv prints the addresses as shell exports.
+func renderEnv(key validatedKey, opts validateOptions) error {
+	if key.err != nil {
+		return key.err
+	}
+	ki := key.ki
+	if err := styleAddresses(&ki, opts.addressStyle); err != nil {
+		return err
+	}
+	fmt.Print(envExports(ki, opts.networkID, opts.hrp, opts.envPrefix, opts.showSecret))
+	return nil
+}
+
+// renderHCL prints the addresses as a Terraform variable map.
+func renderHCL(key validatedKey, opts validateOptions) error {
+	if key.err != nil {
+		return key.err
+	}
+	ki := key.ki
+	if err := styleAddresses(&ki, opts.addressStyle); err != nil {
+		return err
+	}
+	fmt.Print(hclVariableMap(ki, opts.networkID, opts.hrp, opts.hclVariable, opts.showSecret))
+	return nil
+}
+
+// renderText prints the key file as read, then the derived fields and the
+// result. The key file is printed even if it is invalid, before the error.
+func renderText(key validatedKey, opts validateOptions) error {
+	if !key.isDecoded {
+		return key.err
+	}
+	if !opts.quiet {
+		switch {
+		case opts.keyFormat == keyFormatKeyInfo && opts.showSecret && opts.addressStyle == addressStyleFull:
+			fmt.Println(string(key.raw))
+		case opts.keyFormat == keyFormatKeyInfo && opts.addressStyle == addressStyleFull:
+			fmt.Println(string(redactKeyFile(key.raw, key.decoded)))
+		default:
+			displayed := key.decoded
+			if !opts.showSecret {
+				displayed = key.decoded.Redacted()
+			}
+			if err := styleAddresses(&displayed, opts.addressStyle); err != nil {
+				return err
+			}
+			out, err := yaml.Marshal(displayed)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+		}
+	}
+
+	ki := key.ki
+	if opts.verbose && !opts.quiet && (ki.PrivateKey != "" || ki.WatchOnly) {
+		if err := printEncodingSteps(ki, opts.hrp, opts.showSecret); err != nil {
+			return err
+		}
+	}
+	if key.err != nil {
+		return key.err
+	}
+	if err := opts.qr.render(ki, opts.quiet); err != nil {
+		return err
+	}
+	if !opts.quiet {
+		fmt.Printf("network: %s, HRP %q\n", keyinfo.NetworkLabel(opts.networkID), opts.hrp)
+	}
+	if missing := keyinfo.MissingFields(ki); opts.keyFormat == keyFormatKeyInfo && len(missing) > 0 && !opts.quiet {
+		fmt.Printf("populated missing fields: %s (use --migrate to save them)\n", strings.Join(missing, ", "))
+	}
+	if opts.only != "" {
+		if err := styleAddresses(&ki, opts.addressStyle); err != nil {
+			return err
+		}
+		fmt.Println(fieldAddress(ki, opts.only))
+		return nil
+	}
+	if opts.quiet {
+		fmt.Println("SUCCESS")
+		return nil
+	}
+	// the funding instructions always show the full addresses to paste
+	funding := ki
+	if err := styleAddresses(&ki, opts.addressStyle); err != nil {
+		return err
+	}
+	for _, alias := range opts.aliases {
+		fmt.Println(ki.Addresses[alias])
+	}
+	for _, name := range opts.encoders {
+		fmt.Printf("encoder %s: %s\n", name, key.encoded[name])
+	}
+	for _, l := range labelAddresses(ki, opts.labels) {
+		fmt.Printf("%s: %s (%s)\n", l.Field, l.Address, l.Label)
+	}
+	if opts.checkBalance {
+		printBalances(opts.endpoint, funding, opts.networkID)
+	}
+	if opts.noChecksum {
+		if err := printNoChecksum(ki, opts.showSecret); err != nil {
+			return err
+		}
+	}
+	if opts.includePubkey {
+		fmt.Printf("public_key_compressed: %s\n", ki.PublicKeyCompressed)
+		fmt.Printf("public_key_uncompressed: %s\n", ki.PublicKeyUncompressed)
+	}
+	for _, n := range ki.Networks {
+		fmt.Printf("\nnetwork %d (%s)\n", n.NetworkID, n.HRP)
+		fmt.Println(n.XAddress)
+		fmt.Println(n.PAddress)
+		fmt.Println(n.CAddress)
+		for _, alias := range opts.aliases {
+			fmt.Println(n.Addresses[alias])
+		}
+	}
+	if opts.fundingHelp {
+		fmt.Printf("\n%s\n", keyinfo.FundingInstructions(funding, opts.networkID))
+	}
+
+	fmt.Println("SUCCESS")
+	return nil
+}
+
+// printNoChecksum prints the "--no-checksum" encodings of the private key
+// (redacted unless showSecret) and the short address, marked non-standard.
+func printNoChecksum(ki keyinfo.Info, showSecret bool) error {
+	if ki.WatchOnly {
+		return errors.New("--no-checksum requires the private key, not a watch-only key")
+	}
+	pk, err := keyinfo.DecodePrivateKey(ki.PrivateKey)
+	if err != nil {
+		return err
+	}
+	privKey, err := keyinfo.EncodePrivateKeyNoChecksum(pk)
+	if err != nil {
+		return err
+	}
+	if !showSecret {
+		privKey = keyinfo.RedactPrivateKey(privKey)
+	}
+	shortAddr, err := keyinfo.EncodeShortAddrNoChecksum(pk)
+	if err != nil {
+		return err
+	}
+	logger.Warnf("--no-checksum: %s", keyinfo.NoChecksumWarning)
+	fmt.Printf("private_key (NON-STANDARD, no checksum): %s\n", privKey)
+	fmt.Printf("short_address (NON-STANDARD, no checksum): %s\n", shortAddr)
+	return nil
+}
+
+// compareToolTimeout is how long the --compare-tool command may run.
+const compareToolTimeout = 2 * time.Minute
+
+// compareWithTool runs the other key tool command with the key file path and
+// the network ID args, and compares the chain and eth addresses in its output
+// with the validated ones, failing on any divergence. The output is scanned
+// for anything that parses as an address, so the tool may print them in any
+// format (e.g., YAML, JSON, or a table). Each of our addresses must be among
+// the ones the tool prints for the same chain, which may also include the
+// addresses of other networks. An address the tool does not print is
+// reported, but is not a divergence. The comparison table is printed to w.
+func compareWithTool(tool string, fpath string, networkID uint32, ki keyinfo.Info, quiet bool, w io.Writer) error {
+	command := strings.Fields(tool)
+	if len(command) == 0 {
+		return usageError("empty --compare-tool command")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), compareToolTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, command[0], append(command[1:], fpath, strconv.FormatUint(uint64(networkID), 10))...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	logger.Infof("comparing the addresses with %q", cmd.String())
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			logger.Errorf("--compare-tool stderr:\n%s", msg)
+		}
+		return fmt.Errorf("--compare-tool %q failed (%v)", tool, err)
+	}
+
+	found := toolAddresses(stdout.String())
+	if len(found) == 0 {
+		return fmt.Errorf("--compare-tool %q printed no addresses", tool)
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tOURS\tTOOL\tRESULT")
+	different := 0
+	for _, f := range []struct{ name, key, ours string }{
+		{"x_address", "X", ki.XAddress},
+		{"p_address", "P", ki.PAddress},
+		{"c_address", "C", ki.CAddress},
+		{"eth_address", "eth", ki.EthAddress},
+	} {
+		theirs := found[f.key]
+		result := "DIFFERENT"
+		if len(theirs) == 0 {
+			result = "NOT PRINTED"
+		}
+		// the tool may print the addresses of other networks or chain aliases
+		// too, so ours only has to be one of them
+		for _, addr := range theirs {
+			if strings.EqualFold(addr, f.ours) {
+				result = "SAME"
+			}
+		}
+		if result == "DIFFERENT" {
+			different++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.name, f.ours, strings.Join(theirs, " "), result)
+	}
+	if !quiet || different > 0 {
+		tw.Flush()
+	}
+	if different > 0 {
+		return fmt.Errorf("--compare-tool %q computed %d different addresses", tool, different)
+	}
+	return nil
+}
+
+// toolAddresses returns the unique addresses in the output by the chain alias
+// ("X", "P", "C") or "eth", in the order they are printed. Any other word
+// (e.g., a redacted private key, or the field names) is skipped.
+func toolAddresses(out string) map[string][]string {
+	words := strings.FieldsFunc(out, func(r rune) bool {
+		return !(r == '-' || ('0' <= r && r <= '9') || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z'))
+	})
+	found := make(map[string][]string)
+	add := func(key string, addr string) {
+		for _, seen := range found[key] {
+			if strings.EqualFold(seen, addr) {
+				return
+			}
+		}
+		found[key] = append(found[key], addr)
+	}
+	for _, w := range words {
+		if strings.HasPrefix(w, "0x") && common.IsHexAddress(w) && len(w) == 2+2*common.AddressLength {
+			add("eth", w)
+			continue
+		}
+		alias, _, _, err := formatting.ParseAddress(w)
+		if err == nil && (alias == "X" || alias == "P" || alias == "C") {
+			add(alias, w)
+		}
+	}
+	return found
+}
+
+// watchDebounce is how long to wait for the successive writes of a save
+// (e.g., truncate then write) to settle before revalidating.
+const watchDebounce = 200 * time.Millisecond
+
+// watchKeyFile runs the validation, and reruns it whenever the key file
+// changes, until interrupted. The validation errors are printed
+// instead of returned, so the watch keeps going.
+func watchKeyFile(fpath string, run func() error) error {
+	abs, err := filepath.Abs(fpath)
+	if err != nil {
+		return ioError(err)
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return ioError(err)
+	}
+	defer w.Close()
+	// watch the directory, since editors often save by renaming
+	// a new file over the old one, which drops a watch on the file
+	if err := w.Add(filepath.Dir(abs)); err != nil {
+		return ioError(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	revalidate := func() {
+		fmt.Printf("\n--- %s validating %q\n", time.Now().Format("15:04:05"), fpath)
+		if err := run(); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+	}
+	revalidate()
+	logger.Infof("watching %q for changes (Ctrl-C to stop)", fpath)
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Infof("stopped watching %q", fpath)
+			return nil
+		case ev, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if ev.Name == abs && ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0 {
+				debounce = time.After(watchDebounce)
+			}
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			return ioError(err)
+		case <-debounce:
+			debounce = nil
+			revalidate()
+		}
+	}
+}
+
+// withoutFlag removes the boolean flag from the raw args,
+// in any of the "-name", "--name", and "--name=value" forms.
+func withoutFlag(args []string, name string) []string {
+	var kept []string
+	for _, arg := range args {
+		trimmed := strings.TrimLeft(arg, "-")
+		if strings.HasPrefix(arg, "-") && (trimmed == name || strings.HasPrefix(trimmed, name+"=")) {
+			continue
+		}
+		kept = append(kept, arg)
+	}
+	return kept
+}
+
+// printEncodingSteps prints each intermediate value from the private key
+// to the addresses, to debug an address that another tool encodes differently.
+// The private key values are redacted unless showSecret.
+func printEncodingSteps(ki keyinfo.Info, hrp string, showSecret bool) error {
+	if ki.WatchOnly {
+		return usageError("--verbose requires the private key (the key info is watch-only)")
+	}
+	pk, err := keyinfo.DecodePrivateKey(ki.PrivateKey)
+	if err != nil {
+		return err
+	}
+	pkEncoded, err := keyinfo.EncodePrivateKey(pk)
+	if err != nil {
+		return err
+	}
+	pkDecoded, err := keyinfo.DecodePrivateKey(pkEncoded)
+	if err != nil {
+		return err
+	}
+	secret, secretPrivateKey := keyinfo.RedactSecret, keyinfo.RedactPrivateKey
+	if showSecret {
+		secret = func(s string) string { return s }
+		secretPrivateKey = secret
+	}
+
+	pubBytes := pk.PublicKey().Bytes()
+	pubHash := pk.PublicKey().Address().Bytes()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "private key bytes\t0x%s\n", secret(hex.EncodeToString(pk.Bytes())))
+	fmt.Fprintf(tw, "private key CB58\t%s\n", secretPrivateKey(pkEncoded))
+	fmt.Fprintf(tw, "re-decoded private key bytes\t0x%s\n", secret(hex.EncodeToString(pkDecoded.Bytes())))
+	fmt.Fprintf(tw, "public key (compressed)\t0x%x\n", pubBytes)
+	fmt.Fprintf(tw, "public key hash\t0x%x\n", pubHash)
+	for _, chain := range []string{"X", "P", "C"} {
+		addr, err := keyinfo.FormatAddress(chain, hrp, pubHash)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(tw, "%s-chain address (hrp %q)\t%s\n", chain, hrp, addr)
+	}
+	fmt.Fprintf(tw, "short address\t%s\n", keyinfo.EncodeShortAddr(pk))
+	fmt.Fprintf(tw, "eth address\t%s\n", keyinfo.EncodeEthAddr(pk))
+	fmt.Fprintln(tw)
+	return tw.Flush()
+}
+
+// rewriteKeyFile regenerates the key info with all the derived addresses
+// from the private key of the key file (e.g., to add the fields missing
+// in old key files), and writes it in the same format as the input.
+func rewriteKeyFile(fpath string, outPath string, force bool, keyFormat string, networkID uint32, hrp string, aliases []string, networkIDs []uint32) error {
+	if !force {
+		if sameFile(fpath, outPath) {
+			return usageError("--out %q is the input key file (use --force to overwrite)", outPath)
+		}
+		if _, err := os.Stat(outPath); err == nil {
+			return usageError("%q already exists (use --force to overwrite)", outPath)
+		}
+	}
+
+	b, err := readKeyFile(fpath)
+	if err != nil {
+		return err
+	}
+	ki, err := decodeKey(b, keyFormat, networkID, hrp)
+	if err != nil {
+		return err
+	}
+	if missing := keyinfo.MissingFields(ki); keyFormat == keyFormatKeyInfo && len(missing) > 0 {
+		logger.Infof("adding missing fields: %s", strings.Join(missing, ", "))
+	}
+	// every optional field of the key file (e.g., the public keys, and the
+	// additional chains and networks) is re-derived instead of dropped
+	regenerated, _, err := keyinfo.Refresh(ki, networkID, hrp)
+	if err != nil {
+		return err
+	}
+	aliases, networkIDs = withStoredAddresses(regenerated, aliases, networkIDs)
+	if err := addAddresses(&regenerated, aliases, networkIDs, hrp); err != nil {
+		return err
+	}
+
+	var ob []byte
+	switch {
+	case keyFormat != keyFormatKeyInfo:
+		ob, err = marshalInfo(regenerated, outPath)
+	case bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")):
+		ob, err = json.MarshalIndent(regenerated, "", "    ")
+	default:
+		ob, err = yaml.Marshal(regenerated)
+	}
+	if err != nil {
+		return err
+	}
+	if ob, err = sealKeyFile(fpath, ob); err != nil {
+		return err
+	}
+
+	logger.Infof("saving to %q", outPath)
+	if err := ioutil.WriteFile(outPath, ob, fsModeWrite); err != nil {
+		return ioError(err)
+	}
+	return nil
+}
+
+// withStoredAddresses adds the chain aliases and the network IDs of the key
+// info to the --chains and --networks ones, if any is set, since addAddresses
+// replaces the addresses, so the key file keeps its own additional addresses.
+func withStoredAddresses(ki keyinfo.Info, aliases []string, networkIDs []uint32) ([]string, []uint32) {
+	if len(aliases) == 0 && len(networkIDs) == 0 {
+		return aliases, networkIDs
+	}
+	seenAlias := make(map[string]bool)
+	for _, alias := range aliases {
+		seenAlias[alias] = true
+	}
+	addAlias := func(addrs map[string]string) {
+		stored := make([]string, 0, len(addrs))
+		for alias := range addrs {
+			if !seenAlias[alias] {
+				stored = append(stored, alias)
+				seenAlias[alias] = true
+			}
+		}
+		sort.Strings(stored)
+		aliases = append(aliases, stored...)
+	}
+	addAlias(ki.Addresses)
+	for _, n := range ki.Networks {
+		addAlias(n.Addresses)
+	}
+	if len(networkIDs) > 0 {
+		seenNetwork := make(map[uint32]bool)
+		for _, id := range networkIDs {
+			seenNetwork[id] = true
+		}
+		for _, n := range ki.Networks {
+			if !seenNetwork[n.NetworkID] {
+				networkIDs = append(networkIDs, n.NetworkID)
+				seenNetwork[n.NetworkID] = true
+			}
+		}
+	}
+	return aliases, networkIDs
+}
+
+// canonicalizeKeyFile validates the key file, and writes its canonical form
+// (see keyinfo.Canonicalize) as indented JSON to outPath, or to stdout if
+// outPath is empty. The semantically identical key files are written as the
+// same bytes, whatever their input format, so they can be compared with "cmp".
+func canonicalizeKeyFile(fpath string, outPath string, force bool, keyFormat string, networkID uint32, hrp string) error {
+	if outPath != "" && !force {
+		if sameFile(fpath, outPath) {
+			return usageError("--out %q is the input key file (use --force to overwrite)", outPath)
+		}
+		if _, err := os.Stat(outPath); err == nil {
+			return usageError("%q already exists (use --force to overwrite)", outPath)
+		}
+	}
+
+	b, err := readKeyFile(fpath)
+	if err != nil {
+		return err
+	}
+	ki, err := decodeKey(b, keyFormat, networkID, hrp)
+	if err != nil {
+		return err
+	}
+	canonical, err := keyinfo.Canonicalize(ki, networkID, hrp)
+	if err != nil {
+		return err
+	}
+	ob, err := json.MarshalIndent(canonical, "", "    ")
+	if err != nil {
+		return err
+	}
+	ob = append(ob, '\n')
+
+	if outPath == "" {
+		if wasEncrypted(fpath) {
+			return usageError("--canonicalize of the encrypted %q requires --out, so the private key is not printed", fpath)
+		}
+		_, err = os.Stdout.Write(ob)
+		return err
+	}
+	if ob, err = sealKeyFile(fpath, ob); err != nil {
+		return err
+	}
+	logger.Infof("saving the canonical key file to %q", outPath)
+	if err := ioutil.WriteFile(outPath, ob, fsModeWrite); err != nil {
+		return ioError(err)
+	}
+	return nil
+}
+
+// sameFile returns true if both paths exist and are the same file.
+func sameFile(a string, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(ai, bi)
+}
+
+// redactKeyFile redacts the private keys in the key file contents,
+// keeping the rest of the file as is.
+func redactKeyFile(b []byte, ki keyinfo.Info) []byte {
+	if ki.PrivateKey != "" {
+		b = bytes.ReplaceAll(b, []byte(ki.PrivateKey), []byte(keyinfo.RedactPrivateKey(ki.PrivateKey)))
+	}
+	if ki.PrivateKeyHex != "" {
+		b = bytes.ReplaceAll(b, []byte(ki.PrivateKeyHex), []byte(keyinfo.RedactSecret(ki.PrivateKeyHex)))
+	}
+	return b
+}
+
+// validateReport is the "--format json" output of the validation.
+type validateReport struct {
+	KeyInfo   *keyinfo.Info `json:"key_info,omitempty"`
+	NetworkID uint32        `json:"network_id"`
+	HRP       string        `json:"hrp"`
+	Valid     bool          `json:"valid"`
+	Error     string        `json:"error,omitempty"`
+
+	// MissingFields are the optional fields missing in the key file,
+	// populated from the private key.
+	MissingFields []string `json:"missing_fields,omitempty"`
+
+	// EncodedAddresses maps each "--encoder" name to the encoded address.
+	EncodedAddresses map[string]string `json:"encoded_addresses,omitempty"`
+
+	// Labels are the "--labels" of the addresses, in the key file order.
+	Labels []addressLabel `json:"labels,omitempty"`
+}
+
+// addressLabel is the human-readable label of an address (e.g., "validator reward").
+type addressLabel struct {
+	// Field is the key file field of the address (e.g., "p_address").
+	Field   string `json:"field"`
+	Address string `json:"address"`
+	Label   string `json:"label"`
+}
+
+// go run main.go generate 9999 /tmp/test.key.json
+// go run main.go generate 9999 /tmp/test.key.json --force
+// go run main.go generate 9999 /tmp/test.key.json --hrp mynet
+// go run main.go generate 9999 /tmp/test.key.json --qr --qr-chain eth
+// go run main.go generate 9999 /tmp/test.key.json --seed 0x74657374
+// go run main.go generate 9999 --count 20 --out-dir /tmp/keys
+// go run main.go generate 9999 --count 100000 --out-dir /tmp/keys --timeout 1m
+// go run main.go generate /tmp/fuji.key.json --network fuji
+// go run main.go generate /tmp/fuji.key.json --network fuji --funding-help
+func generate(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	force := fs.Bool("force", false, "overwrite the output file if it already exists (or write to a non-empty --out-dir)")
+	count := fs.Int("count", 1, "number of keys to generate into --out-dir")
+	outDir := fs.String("out-dir", "", "directory to write the \"key-[INDEX].json\" files to")
+	workers := fs.Int("workers", runtime.NumCPU(), "number of keys to generate in parallel with --out-dir")
+	timeout := timeoutFlag(fs)
+	chains := chainsFlag(fs)
+	networks := networksFlag(fs)
+	includePubkey := includePubkeyFlag(fs)
+	hrpOverride := hrpFlag(fs)
+	qr := registerQRFlags(fs)
+	strictNetwork := strictNetworkFlag(fs)
+	seed := fs.String("seed", "", "hex-encoded seed to deterministically derive the key from (NOT for production)")
+	fundingHelp := fundingHelpFlag(fs)
+	networkName := networkFlag(fs)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	// [NETWORK-ID] may be omitted with --network
+	nargs := 2
+	if *outDir != "" {
+		nargs = 1
+	}
+	if *networkName != "" && len(args) == nargs-1 {
+		args = append([]string{""}, args...)
+	}
+	switch {
+	case *outDir != "":
+		if len(args) != 1 {
+			return usageError("expected 1 arg with --out-dir: generate [NETWORK-ID], got %q", args)
+		}
+		if *count < 1 {
+			return usageError("invalid --count %d", *count)
+		}
+		if *workers < 1 {
+			return usageError("invalid --workers %d", *workers)
+		}
+		if *seed != "" || *qr.qr || *fundingHelp {
+			return usageError("--seed, --qr, and --funding-help cannot be used with --out-dir")
+		}
+	case *count != 1:
+		return usageError("--count requires --out-dir")
+	case *timeout != 0:
+		return usageError("--timeout requires --out-dir")
+	case len(args) != 2:
+		return usageError("expected 2 args: generate [NETWORK-ID] [OUTPUT-PATH], got %q", args)
+	}
+	if err := qr.check(); err != nil {
+		return err
+	}
+	var seedBytes []byte
+	if *seed != "" {
+		seedBytes, err = hex.DecodeString(strings.TrimPrefix(*seed, "0x"))
+		if err != nil {
+			return usageError("invalid --seed (%v)", err)
+		}
+	}
+	aliases, err := parseChains(*chains)
+	if err != nil {
+		return err
+	}
+	networkIDs, err := parseNetworks(*networks)
+	if err != nil {
+		return err
+	}
+
+	networkID, err := resolveNetwork(*networkName, args[0])
+	if err != nil {
+		return err
+	}
+	if err := checkNetworkID(networkID, *strictNetwork); err != nil {
+		return err
+	}
+	hrp, err := resolveHRP(networkID, *hrpOverride)
+	if err != nil {
+		return err
+	}
+	warnNetworkHRP(networkID, hrp)
+	if *outDir != "" {
+		if *timeout < 0 {
+			return usageError("invalid --timeout %s", *timeout)
+		}
+		ctx, cancel := timeoutContext(*timeout)
+		defer cancel()
+		return generateDir(ctx, *outDir, *count, *workers, *force, networkID, hrp, aliases, networkIDs, *includePubkey)
+	}
+	logger.Infof("generating key for network %s with HRP %q", keyinfo.NetworkLabel(networkID), hrp)
+	fpath := args[1]
+	if _, err := os.Stat(fpath); err == nil && !*force {
+		return usageError("%q already exists (use --force to overwrite)", fpath)
+	}
+
+	var pk *crypto.PrivateKeySECP256K1R
+	if seedBytes != nil {
+		logger.Warnf("the key is derived from --seed, and is NOT for production use")
+		pk, err = keyinfo.NewPrivateKeyFromSeed(seedBytes)
+	} else {
+		pk, err = keyinfo.NewPrivateKey()
+	}
+	if err != nil {
+		return err
+	}
+	ki, err := keyinfo.NewInfoFromPrivateKeyWithHRP(pk, networkID, hrp)
+	if err != nil {
+		return err
+	}
+	if err := addAddresses(&ki, aliases, networkIDs, hrp); err != nil {
+		return err
+	}
+	if err := addPublicKeys(&ki, *includePubkey); err != nil {
+		return err
+	}
+	b, err := marshalInfo(ki, fpath)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("saving to %q", fpath)
+	if err := ioutil.WriteFile(fpath, b, fsModeWrite); err != nil {
+		return ioError(err)
+	}
+
+	fmt.Println(ki.XAddress)
+	fmt.Println(ki.PAddress)
+	fmt.Println(ki.CAddress)
+	fmt.Println(ki.EthAddress)
+	if *fundingHelp {
+		fmt.Printf("\n%s", keyinfo.FundingInstructions(ki, networkID))
+	}
+	return qr.render(ki, false)
+}
+
+// generateDir generates the number of keys in parallel, writes each to
+// "key-[INDEX].json" in the directory, and prints the X-chain address index.
+// Once the context is done, no more keys are started, and the keys
+// generated so far are printed.
+func generateDir(ctx context.Context, dir string, count int, workers int, force bool, networkID uint32, hrp string, aliases []string, networkIDs []uint32, includePubkey bool) error {
+	entries, err := ioutil.ReadDir(dir)
+	switch {
+	case os.IsNotExist(err):
+		if err := os.MkdirAll(dir, fsModeDir); err != nil {
+			return ioError(err)
+		}
+	case err != nil:
+		return ioError(err)
+	case len(entries) > 0 && !force:
+		return usageError("%q is not empty (use --force to write to it anyway)", dir)
+	}
+	logger.Infof("generating %d keys for network %s with HRP %q and %d workers", count, keyinfo.NetworkLabel(networkID), hrp, workers)
+
+	indexc := make(chan int)
+	resultc := make(chan generateResult)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexc {
+				fpath := filepath.Join(dir, fmt.Sprintf("key-%d.json", idx))
+				ki, err := generateFile(fpath, networkID, hrp, aliases, networkIDs, includePubkey)
+				resultc <- generateResult{index: idx, fpath: fpath, xAddress: ki.XAddress, err: err}
+			}
+		}()
+	}
+	go func() {
+		defer func() {
+			close(indexc)
+			wg.Wait()
+			close(resultc)
+		}()
+		for idx := 0; idx < count; idx++ {
+			select {
+			case indexc <- idx:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	results := make([]generateResult, count)
+	generated := 0
+	for res := range resultc {
+		results[res.index] = res
+		generated++
+	}
+
+	// duplicates are statistically impossible,
+	// but a broken random source must not go unnoticed
+	seen := make(map[string]string, count)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tX-ADDRESS")
+	for _, res := range results {
+		if res.fpath == "" {
+			// not started before the timeout
+			continue
+		}
+		if res.err != nil {
+			return res.err
+		}
+		if prev, ok := seen[res.xAddress]; ok {
+			return fmt.Errorf("duplicate key generated in %q and %q (%s)", prev, res.fpath, res.xAddress)
+		}
+		seen[res.xAddress] = res.fpath
+		fmt.Fprintf(tw, "%s\t%s\n", res.fpath, res.xAddress)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if generated < count {
+		return timeoutError("timed out (%v), generated %d of %d keys", ctx.Err(), generated, count)
+	}
+	return nil
+}
+
+// generateFile generates a new key and writes its key info to the file.
+func generateFile(fpath string, networkID uint32, hrp string, aliases []string, networkIDs []uint32, includePubkey bool) (keyinfo.Info, error) {
+	pk, err := keyinfo.NewPrivateKey()
+	if err != nil {
+		return keyinfo.Info{}, err
+	}
+	ki, err := keyinfo.NewInfoFromPrivateKeyWithHRP(pk, networkID, hrp)
+	if err != nil {
+		return keyinfo.Info{}, err
+	}
+	if err := addAddresses(&ki, aliases, networkIDs, hrp); err != nil {
+		return keyinfo.Info{}, err
+	}
+	if err := addPublicKeys(&ki, includePubkey); err != nil {
+		return keyinfo.Info{}, err
+	}
+	b, err := marshalInfo(ki, fpath)
+	if err != nil {
+		return keyinfo.Info{}, err
+	}
+	if err := ioutil.WriteFile(fpath, b, fsModeWrite); err != nil {
+		return keyinfo.Info{}, ioError(err)
+	}
+	return ki, nil
+}
+
+// The file names of "generate-staker" in the output directory.
+// The wallet key file is a "*.key.json", so validate-dir checks it.
+const (
+	stakerKeyFile  = "staker.key"
+	stakerCertFile = "staker.crt"
+	walletKeyFile  = "wallet.key.json"
+)
+
+// go run main.go generate-staker /tmp/node1
+// go run main.go generate-staker /tmp/node1 9999
+// go run main.go generate-staker /tmp/node1 --network fuji --force
+//
+// Writes the avalanchego staking TLS key pair to "staker.key" and "staker.crt"
+// (for "--staking-tls-key-file" and "--staking-tls-cert-file"), and with the
+// [NETWORK-ID] (or --network), a wallet key to "wallet.key.json".
+// Prints the NodeID of the certificate, then the wallet addresses.
+func generateStaker(args []string) error {
+	fs := flag.NewFlagSet("generate-staker", flag.ContinueOnError)
+	force := fs.Bool("force", false, "overwrite the files if they already exist")
+	hrpOverride := hrpFlag(fs)
+	strictNetwork := strictNetworkFlag(fs)
+	networkName := networkFlag(fs)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 && len(args) != 2 {
+		return usageError("expected 1 or 2 args: generate-staker [DIR-PATH] [NETWORK-ID], got %q", args)
+	}
+	dir := args[0]
+	wallet := len(args) == 2 || *networkName != ""
+	if !wallet && *hrpOverride != "" {
+		return usageError("--hrp requires the [NETWORK-ID] arg or --network")
+	}
+	fpaths := []string{filepath.Join(dir, stakerKeyFile), filepath.Join(dir, stakerCertFile)}
+	if wallet {
+		fpaths = append(fpaths, filepath.Join(dir, walletKeyFile))
+	}
+	for _, fpath := range fpaths {
+		if _, err := os.Stat(fpath); err == nil && !*force {
+			return usageError("%q already exists (use --force to overwrite)", fpath)
+		}
+	}
+	var networkID uint32
+	var hrp string
+	if wallet {
+		networkID, err = resolveNetwork(*networkName, optionalArg(args, 1))
+		if err != nil {
+			return err
+		}
+		if err := checkNetworkID(networkID, *strictNetwork); err != nil {
+			return err
+		}
+		hrp, err = resolveHRP(networkID, *hrpOverride)
+		if err != nil {
+			return err
+		}
+		warnNetworkHRP(networkID, hrp)
+	}
+	if err := os.MkdirAll(dir, fsModeDir); err != nil {
+		return ioError(err)
+	}
+
+	logger.Infof("generating the staking TLS key pair (RSA 4096) to %q", dir)
+	certPEM, keyPEM, err := staking.NewCertAndKeyBytes()
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(fpaths[0], keyPEM, fsModeWrite); err != nil {
+		return ioError(err)
+	}
+	if err := ioutil.WriteFile(fpaths[1], certPEM, fsModeWrite); err != nil {
+		return ioError(err)
+	}
+	// the NodeID of the written files, as avalanchego loads them,
+	// must be the NodeID of the generated certificate
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return errors.New("generated an invalid certificate PEM")
+	}
+	nodeID, err := keyinfo.NodeIDFromCert(block.Bytes)
+	if err != nil {
+		return err
+	}
+	loaded, err := keyinfo.LoadNodeID(fpaths[0], fpaths[1])
+	if err != nil {
+		return err
+	}
+	if loaded != nodeID {
+		return fmt.Errorf("the written staking key pair has NodeID %s, expected %s", loaded, nodeID)
+	}
+	fmt.Println(nodeID)
+	if !wallet {
+		return nil
+	}
+
+	logger.Infof("generating the wallet key for network %s with HRP %q to %q", keyinfo.NetworkLabel(networkID), hrp, fpaths[2])
+	ki, err := generateFile(fpaths[2], networkID, hrp, nil, nil, false)
+	if err != nil {
+		return err
+	}
+	fmt.Println(ki.XAddress)
+	fmt.Println(ki.PAddress)
+	fmt.Println(ki.CAddress)
+	fmt.Println(ki.EthAddress)
+	return nil
+}
+
+type generateResult struct {
+	index    int
+	fpath    string
+	xAddress string
+	err      error
+}
+
+const (
+	fsModeWrite = 0o600
+	fsModeDir   = 0o700
+)
+
+// go run main.go vanity 9999 abc /tmp/vanity.key.json
+// go run main.go vanity 9999 abc /tmp/vanity.key.json --workers 8 --max-attempts 1000000
+//
+// Each additional prefix character makes the search ~32 times slower
+// (e.g., 4 characters take ~1 million attempts on average).
+func vanity(args []string) error {
+	fs := flag.NewFlagSet("vanity", flag.ContinueOnError)
+	workers := fs.Int("workers", runtime.NumCPU(), "number of goroutines generating keys")
+	maxAttempts := fs.Uint64("max-attempts", 100000000, "number of keys to generate before giving up")
+	force := fs.Bool("force", false, "overwrite the output file if it already exists")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 3 {
+		return usageError("expected 3 args: vanity [NETWORK-ID] [PREFIX] [OUTPUT-PATH], got %q", args)
+	}
+	if *workers < 1 {
+		return usageError("invalid --workers %d", *workers)
+	}
+	if *maxAttempts < 1 {
+		return usageError("invalid --max-attempts %d", *maxAttempts)
+	}
+	networkID, err := parseNetworkID(args[0])
+	if err != nil {
+		return err
+	}
+	prefix := args[1]
+	if err := keyinfo.ValidateVanityPrefix(prefix); err != nil {
+		return usageError("%v", err)
+	}
+	fpath := args[2]
+	if _, err := os.Stat(fpath); err == nil && !*force {
+		return usageError("%q already exists (use --force to overwrite)", fpath)
+	}
+
+	logger.Infof("searching for X-%s1%s... with %d workers", constants.GetHRP(networkID), prefix, *workers)
+	pk, attempts, err := keyinfo.FindVanityKey(networkID, prefix, *workers, *maxAttempts)
+	if err != nil {
+		return fmt.Errorf("%v (%d attempts)", err, attempts)
+	}
+	ki, err := keyinfo.NewInfoFromPrivateKey(pk, networkID)
+	if err != nil {
+		return err
+	}
+	b, err := marshalInfo(ki, fpath)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("saving to %q", fpath)
+	if err := ioutil.WriteFile(fpath, b, fsModeWrite); err != nil {
+		return ioError(err)
+	}
+
+	fmt.Printf("found after %d attempts\n", attempts)
+	fmt.Printf("key file: %s\n", fpath)
+	fmt.Println(ki.XAddress)
+	return nil
+}
+
+// go run main.go from-mnemonic "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about" 0 9999
+// go run main.go from-mnemonic "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about" 0 9999 --eth-accounts 5
+// go run main.go from-mnemonic [XPRV] 0 9999 --eth-accounts 5
+// go run main.go from-mnemonic "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about" 0 9999 --hd-range 0-9
+//
+// The XPRV is the BIP32 root extended private key of the HD wallet seed,
+// which derives the same keys as its mnemonic.
+func fromMnemonic(args []string) error {
+	fs := flag.NewFlagSet("from-mnemonic", flag.ContinueOnError)
+	chains := chainsFlag(fs)
+	networks := networksFlag(fs)
+	includePubkey := includePubkeyFlag(fs)
+	hrpOverride := hrpFlag(fs)
+	ethAccounts := fs.Uint("eth-accounts", 0, "number of C-chain eth accounts to derive at \"m/44'/60'/0'/0/[INDEX]\" (same as Core and MetaMask)")
+	hdRange := fs.String("hd-range", "", fmt.Sprintf("account index range to also derive the addresses at \"m/44'/9000'/0'/0/[INDEX]\" for (e.g., 0-9, at most %d indices)", keyinfo.MaxHDRange))
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 3 {
+		return usageError("expected 3 args: from-mnemonic [MNEMONIC|XPRV] [ACCOUNT-INDEX] [NETWORK-ID], got %d", len(args))
+	}
+	aliases, err := parseChains(*chains)
+	if err != nil {
+		return err
+	}
+	networkIDs, err := parseNetworks(*networks)
+	if err != nil {
+		return err
+	}
+	var hdFirst, hdLast uint32
+	if *hdRange != "" {
+		if hdFirst, hdLast, err = keyinfo.ParseHDRange(*hdRange); err != nil {
+			return usageError("invalid --hd-range (%v)", err)
+		}
+	}
+
+	accountIndex, err := strconv.ParseUint(args[1], 10, 32)
+	if err != nil {
+		return usageError("invalid account index %q (%v)", args[1], err)
+	}
+	networkID, err := parseNetworkID(args[2])
+	if err != nil {
+		return err
+	}
+	hrp, err := resolveHRP(networkID, *hrpOverride)
+	if err != nil {
+		return err
+	}
+
+	master, err := keyinfo.DecodeMasterKey(args[0])
+	if err != nil {
+		return err
+	}
+	logger.Infof("deriving key at %q", keyinfo.DerivationPath(uint32(accountIndex)))
+	pk, err := keyinfo.DecodePrivateKeyFromMaster(master, uint32(accountIndex))
+	if err != nil {
+		return err
+	}
+
+	ki, err := keyinfo.NewInfoFromPrivateKeyWithHRP(pk, networkID, hrp)
+	if err != nil {
+		return err
+	}
+	if err := addAddresses(&ki, aliases, networkIDs, hrp); err != nil {
+		return err
+	}
+	if err := addPublicKeys(&ki, *includePubkey); err != nil {
+		return err
+	}
+	if *ethAccounts > 0 {
+		ki.EthAccounts, err = keyinfo.DeriveEthAccounts(master, uint32(*ethAccounts))
+		if err != nil {
+			return err
+		}
+	}
+	if *hdRange != "" {
+		logger.Infof("deriving the accounts at %q to %q", keyinfo.DerivationPath(hdFirst), keyinfo.DerivationPath(hdLast))
+		ki.HDAccounts, err = keyinfo.DeriveHDAccounts(master, hdFirst, hdLast, networkID, hrp)
+		if err != nil {
+			return err
+		}
+	}
+	b, err := yaml.Marshal(ki)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(b))
+	return nil
+}
+
+// go run main.go from-xpub [XPUB] 0 9999
+//
+// The xpub is the account extended public key at "m/44'/9000'/0'"
+// (e.g., exported from the Ledger Avalanche app), and the addresses are
+// derived at "m/44'/9000'/0'/0/[ACCOUNT-INDEX]" without the private key,
+// same as "from-mnemonic" with the same account index.
+func fromXpub(args []string) error {
+	fs := flag.NewFlagSet("from-xpub", flag.ContinueOnError)
+	hrpOverride := hrpFlag(fs)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 3 {
+		return usageError("expected 3 args: from-xpub [XPUB] [ACCOUNT-INDEX] [NETWORK-ID], got %d", len(args))
+	}
+
+	accountIndex, err := strconv.ParseUint(args[1], 10, 32)
+	if err != nil {
+		return usageError("invalid account index %q (%v)", args[1], err)
+	}
+	networkID, err := parseNetworkID(args[2])
+	if err != nil {
+		return err
+	}
+	hrp, err := resolveHRP(networkID, *hrpOverride)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("deriving watch-only key at %q", keyinfo.DerivationPath(uint32(accountIndex)))
+	pubBytes, err := keyinfo.DecodePublicKeyFromXpub(args[0], uint32(accountIndex))
+	if err != nil {
+		return err
+	}
+	ki, err := keyinfo.NewWatchOnlyInfo(pubBytes, networkID, hrp)
+	if err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(ki)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(b))
+	return nil
+}
+
+// go run main.go ewoq 12345
+// go run main.go ewoq 9999 --chains X,P,C,mychain
+// go run main.go ewoq --network local
+func ewoq(args []string) error {
+	fs := flag.NewFlagSet("ewoq", flag.ContinueOnError)
+	chains := chainsFlag(fs)
+	networks := networksFlag(fs)
+	includePubkey := includePubkeyFlag(fs)
+	hrpOverride := hrpFlag(fs)
+	networkName := networkFlag(fs)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return usageError("expected 1 arg: ewoq [NETWORK-ID], got %q", args)
+	}
+	aliases, err := parseChains(*chains)
+	if err != nil {
+		return err
+	}
+	networkIDs, err := parseNetworks(*networks)
+	if err != nil {
+		return err
+	}
+	networkID, err := resolveNetwork(*networkName, optionalArg(args, 0))
+	if err != nil {
+		return err
+	}
+	hrp, err := resolveHRP(networkID, *hrpOverride)
+	if err != nil {
+		return err
+	}
+
+	// the ewoq key constant always has the avalanchego prefix
+	pk, err := keyinfo.DecodePrivateKey(keyinfo.PrivateKeyPrefix() + strings.TrimPrefix(keyinfo.EwoqPrivateKey, keyinfo.DefaultPrivateKeyPrefix))
+	if err != nil {
+		return err
+	}
+	ki, err := keyinfo.NewInfoFromPrivateKeyWithHRP(pk, networkID, hrp)
+	if err != nil {
+		return err
+	}
+	if err := addAddresses(&ki, aliases, networkIDs, hrp); err != nil {
+		return err
+	}
+	if err := addPublicKeys(&ki, *includePubkey); err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(ki)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(b))
+	return nil
+}
+
+// go run main.go identify X-avax18jma8ppw3nhx5r4ap8clazz0dps7rv5ukulre5
+// go run main.go identify 6Y3kysjF9jnHnYkdS9yGAuoHyae2eNmeV
+// go run main.go identify 0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC
+func identify(args []string) error {
+	fs := flag.NewFlagSet("identify", flag.ContinueOnError)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return usageError("expected 1 arg: identify [ADDRESS], got %q", args)
+	}
+	ai, err := keyinfo.IdentifyAddress(args[0])
+	if err != nil {
+		return err
+	}
+	switch ai.Type {
+	case keyinfo.AddressTypeChain:
+		fmt.Printf("type: %s chain address\n", ai.Encoding)
+		if ai.Encoding == keyinfo.EncodingBech32m {
+			logger.Warnf("%v", keyinfo.ErrBech32m)
+		}
+		fmt.Printf("chain alias: %s\n", ai.ChainIDAlias)
+		fmt.Printf("HRP: %s\n", ai.HRP)
+		fmt.Printf("network: %s\n", keyinfo.HRPNetworkLabel(ai.HRP))
+		fmt.Printf("public key hash: 0x%x\n", ai.Hash)
+	case keyinfo.AddressTypeShort:
+		fmt.Println("type: CB58 short address (network independent)")
+		fmt.Printf("public key hash: 0x%x\n", ai.Hash)
+	case keyinfo.AddressTypeEth:
+		fmt.Println("type: eth address (C-chain EVM account)")
+		fmt.Printf("keccak256 public key hash (last 20 bytes): 0x%x\n", ai.Hash)
+	}
+	return nil
+}
+
+// go run main.go short-to-nodeid 7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg
+func shortToNodeID(args []string) error {
+	fs := flag.NewFlagSet("short-to-nodeid", flag.ContinueOnError)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return usageError("expected 1 arg: short-to-nodeid [SHORT-ADDRESS], got %q", args)
+	}
+	nodeID, err := keyinfo.ShortAddressToNodeID(args[0])
+	if err != nil {
+		return err
+	}
+	logger.Infof("a NodeID is the hash of the staking certificate (see node-id-load), not of a secp256k1 key")
+	fmt.Println(nodeID)
+	return nil
+}
+
+// go run main.go nodeid-to-short NodeID-7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg
+func nodeIDToShort(args []string) error {
+	fs := flag.NewFlagSet("nodeid-to-short", flag.ContinueOnError)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return usageError("expected 1 arg: nodeid-to-short [NODE-ID], got %q", args)
+	}
+	shortAddr, err := keyinfo.NodeIDToShortAddress(args[0])
+	if err != nil {
+		return err
+	}
+	logger.Infof("a NodeID is the hash of the staking certificate (see node-id-load), not of a secp256k1 key")
+	fmt.Println(shortAddr)
+	return nil
+}
+
+// go run main.go owner-id ../../artifacts/ewoq.key.json
+// go run main.go owner-id X-avax18jma8ppw3nhx5r4ap8clazz0dps7rv5ukulre5
+// go run main.go owner-id 6Y3kysjF9jnHnYkdS9yGAuoHyae2eNmeV
+//
+// Prints the 20-byte address hash that the X and P-chain UTXO outputs
+// (secp256k1fx.OutputOwners) reference the owner by, and its CB58 form
+// (ids.ShortID) in the transaction and UTXO JSON. Both are the same on
+// every chain, HRP, and network, so a UTXO is the key's if any of its
+// owner addresses is this hash, whatever address encoding it was sent to.
+func ownerID(args []string) error {
+	fs := flag.NewFlagSet("owner-id", flag.ContinueOnError)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return usageError("expected 1 arg: owner-id [KEY-PATH or ADDRESS], got %q", args)
+	}
+	var hash []byte
+	if ai, err := keyinfo.IdentifyAddress(args[0]); err == nil {
+		if ai.Type == keyinfo.AddressTypeEth {
+			return fmt.Errorf("%s is a C-chain EVM account, which owns no UTXOs (use the C-chain address of the key for the atomic UTXOs)", args[0])
+		}
+		hash = ai.Hash
+	} else {
+		ki, err := loadKeyFile(args[0])
+		if err != nil {
+			return err
+		}
+		if ki.WatchOnly {
+			_, _, hash, err = formatting.ParseAddress(ki.XAddress)
+			if err != nil {
+				return fmt.Errorf("invalid X-chain address %q (%v)", ki.XAddress, err)
+			}
+		} else {
+			pk, err := keyinfo.DecodePrivateKey(ki.PrivateKey)
+			if err != nil {
+				return err
+			}
+			hash = pk.PublicKey().Address().Bytes()
+		}
+	}
+	owner, err := ids.ToShortID(hash)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("owner hash: 0x%x\n", owner.Bytes())
+	fmt.Printf("owner ID: %s\n", owner)
+	return nil
+}
+
+// go run main.go explain-c ../../artifacts/ewoq.key.json 9999
+// go run main.go explain-c /tmp/ledger.key.json 1
+//
+// Shows the two C-chain formats of the key side by side, after checking
+// that both were derived from the public key of the file (the private key,
+// or "public_key_compressed" of the watch-only key files).
+func explainC(args []string) error {
+	fs := flag.NewFlagSet("explain-c", flag.ContinueOnError)
+	hrpOverride := hrpFlag(fs)
+	networkName := networkFlag(fs)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 && len(args) != 2 {
+		return usageError("expected 1 or 2 args: explain-c [KEY-PATH] [NETWORK-ID], got %q", args)
+	}
+	networkID, err := resolveFileNetworkID(args, *networkName, keyFormatKeyInfo)
+	if err != nil {
+		return err
+	}
+	hrp, err := resolveHRP(networkID, *hrpOverride)
+	if err != nil {
+		return err
+	}
+	ki, err := loadKeyFile(args[0])
+	if err != nil {
+		return err
+	}
+	// fails on an eth_address of another key (keyinfo.ErrSplicedEthAddress)
+	if err := keyinfo.ValidateWithHRP(ki, networkID, hrp); err != nil {
+		return err
+	}
+
+	var pubBytes []byte
+	if ki.WatchOnly {
+		if pubBytes, err = hex.DecodeString(ki.PublicKeyCompressed); err != nil {
+			return fmt.Errorf("invalid public_key_compressed %q (%w)", ki.PublicKeyCompressed, err)
+		}
+	} else {
+		pk, err := keyinfo.DecodePrivateKey(ki.PrivateKey)
+		if err != nil {
+			return err
+		}
+		pubBytes = pk.PublicKey().Bytes()
+	}
+	ecdsaPub, err := eth_crypto.DecompressPubkey(pubBytes)
+	if err != nil {
+		return err
+	}
+	pubHash := hashing.PubkeyBytesToAddress(pubBytes)
+	cAddr, err := keyinfo.FormatAddress("C", hrp, pubHash)
+	if err != nil {
+		return err
+	}
+	ethAddr := eth_crypto.PubkeyToAddress(*ecdsaPub).Hex()
+
+	fmt.Printf("public key (33-byte compressed secp256k1):\n  0x%x\n\n", pubBytes)
+	fmt.Printf("c_address (atomic import/export with the X and P-chains):\n  %s\n", cAddr)
+	fmt.Printf("  = \"C-\" + bech32(hrp %q, ripemd160(sha256(public key)) = 0x%x)\n", hrp, pubHash)
+	fmt.Printf("eth_address (EVM balances and transactions, e.g., MetaMask and Core):\n  %s\n", ethAddr)
+	fmt.Printf("  = last 20 bytes of keccak256(64-byte uncompressed public key 0x%x)\n\n", eth_crypto.FromECDSAPub(ecdsaPub)[1:])
+	fmt.Println("both are the same C-chain account of the same key in two formats, which look unrelated")
+	fmt.Println("characters since they hash the public key differently: the AVAX exported to c_address")
+	fmt.Println("is imported into the EVM balance of eth_address")
+	fmt.Println("SUCCESS: c_address and eth_address derive from the same public key")
+	return nil
+}
+
+// go run main.go explain ../../artifacts/ewoq.key.json 9999
+//
+// X, P, and short addresses (and the C-chain bech32 address) all encode the
+// same 20-byte public key hash, while the eth address is derived from the
+// keccak256 hash of the full public key. No address has an asset ID: the
+// X-chain address of AVAX is the address of every other X-chain asset.
+func explain(args []string) error {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return usageError("expected 2 args: explain [KEY-PATH] [NETWORK-ID], got %q", args)
+	}
+	networkID, err := parseNetworkID(args[1])
+	if err != nil {
+		return err
+	}
+	ki, err := loadKeyFile(args[0])
+	if err != nil {
+		return err
+	}
+	pk, err := keyinfo.DecodePrivateKey(ki.PrivateKey)
+	if err != nil {
+		return err
+	}
+	derived, err := keyinfo.NewInfoFromPrivateKey(pk, networkID)
+	if err != nil {
+		return err
+	}
+
+	pubBytes := pk.PublicKey().Bytes()
+	pubHash := hashing.PubkeyBytesToAddress(pubBytes)
+	if !bytes.Equal(pubHash, pk.PublicKey().Address().Bytes()) {
+		return fmt.Errorf("public key hash %x != %s", pubHash, pk.PublicKey().Address())
+	}
+	for _, addr := range []string{derived.XAddress, derived.PAddress, derived.CAddress} {
+		_, _, b, err := formatting.ParseAddress(addr)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(b, pubHash) {
+			return fmt.Errorf("%s does not encode the public key hash %x", addr, pubHash)
+		}
+	}
+	hrp := constants.GetHRP(networkID)
+
+	fmt.Printf("public key (33-byte compressed secp256k1):\n  0x%x\n", pubBytes)
+	fmt.Printf("public key hash (20 bytes, ripemd160(sha256(public key))):\n  0x%x\n\n", pubHash)
+	fmt.Printf("X-chain address:\n  %s\n  = \"X-\" + bech32(hrp %q, public key hash)\n", derived.XAddress, hrp)
+	fmt.Println("  same for AVAX and every other X-chain asset (each UTXO has the asset ID, the address does not)")
+	fmt.Printf("P-chain address:\n  %s\n  = \"P-\" + bech32(hrp %q, public key hash)\n", derived.PAddress, hrp)
+	fmt.Println("  also usable as the validator/delegator reward owner (see reward-address)")
+	fmt.Printf("C-chain address (atomic import/export only):\n  %s\n  = \"C-\" + bech32(hrp %q, public key hash)\n", derived.CAddress, hrp)
+	fmt.Printf("short address (network independent):\n  %s\n  = CB58(public key hash + last 4 bytes of sha256(public key hash))\n\n", derived.ShortAddress)
+
+	ethPub := eth_crypto.FromECDSAPub(&pk.ToECDSA().PublicKey)
+	fmt.Printf("eth address (C-chain EVM accounts):\n  %s\n", derived.EthAddress)
+	fmt.Printf("  = last 20 bytes of keccak256(64-byte uncompressed public key 0x%x)\n", ethPub[1:])
+	fmt.Println("  which differs from the public key hash above (not ripemd160(sha256(...)))")
+	return nil
+}
+
+// go run main.go diff ../../artifacts/ewoq.key.json /tmp/ewoq.key.yaml 9999
+// go run main.go diff ../../artifacts/ewoq.key.json /tmp/ewoq.hex.key 9999 --b-key-format hex
+func diffKeys(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	aKeyFormat := fs.String("a-key-format", keyFormatKeyInfo, "first key file format (keyinfo, hex, subnet-cli, base64)")
+	bKeyFormat := fs.String("b-key-format", keyFormatKeyInfo, "second key file format (keyinfo, hex, subnet-cli, base64)")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 3 {
+		return usageError("expected 3 args: diff [KEY-PATH-A] [KEY-PATH-B] [NETWORK-ID], got %q", args)
+	}
+	networkID, err := parseNetworkID(args[2])
+	if err != nil {
+		return err
+	}
+	hrp := constants.GetHRP(networkID)
+
+	var infos [2]keyinfo.Info
+	var raws [2][]byte
+	for i, f := range []struct{ fpath, keyFormat string }{
+		{args[0], *aKeyFormat},
+		{args[1], *bKeyFormat},
+	} {
+		b, err := readKeyFile(f.fpath)
+		if err != nil {
+			return err
+		}
+		ki, err := decodeKey(b, f.keyFormat, networkID, hrp)
+		if err != nil {
+			return fmt.Errorf("failed to decode %q (%w)", f.fpath, err)
+		}
+		pk, err := keyinfo.DecodePrivateKey(ki.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("failed to decode %q (%w)", f.fpath, err)
+		}
+		raws[i] = pk.Bytes()
+		infos[i], err = keyinfo.NewInfoFromPrivateKey(pk, networkID)
+		if err != nil {
+			return err
+		}
+	}
+
+	same := keyinfo.SecretEqual(raws[0], raws[1])
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tA\tB\tRESULT")
+	fmt.Fprintf(tw, "private_key\t%s\t%s\t%s\n",
+		keyinfo.RedactPrivateKey(infos[0].PrivateKey), keyinfo.RedactPrivateKey(infos[1].PrivateKey), diffResult(same))
+	for _, f := range []struct{ name, a, b string }{
+		{"x_address", infos[0].XAddress, infos[1].XAddress},
+		{"p_address", infos[0].PAddress, infos[1].PAddress},
+		{"c_address", infos[0].CAddress, infos[1].CAddress},
+		{"short_address", infos[0].ShortAddress, infos[1].ShortAddress},
+		{"eth_address", infos[0].EthAddress, infos[1].EthAddress},
+	} {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.name, f.a, f.b, diffResult(f.a == f.b))
+	}
+	tw.Flush()
+
+	if !same {
+		return fmt.Errorf("%q and %q are different keys", args[0], args[1])
+	}
+	fmt.Println("\nSAME KEY")
+	return nil
+}
+
+// diffResult returns the RESULT column of the diff.
+func diffResult(same bool) string {
+	if same {
+		return "SAME"
+	}
+	return "DIFFERENT"
+}
+
+// go run main.go reward-address ../../artifacts/ewoq.key.json 9999
+// go run main.go reward-address ../../artifacts/ewoq.key.json 9999 --reward-key /tmp/reward.key.json
+//
+// Prints the P-chain reward owner address for the validator (or delegator)
+// key. The reward owner is any P-chain address, which is the key's own
+// "p_address" by default, or the P-chain address of a separate reward key
+// (e.g., a cold key), so that the staking key never holds the rewards.
+func rewardAddress(args []string) error {
+	fs := flag.NewFlagSet("reward-address", flag.ContinueOnError)
+	rewardKey := fs.String("reward-key", "", "separate key file to derive the reward address from")
+	hrpOverride := hrpFlag(fs)
+	networkName := networkFlag(fs)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 && len(args) != 2 {
+		return usageError("expected 1 or 2 args: reward-address [KEY-PATH] [NETWORK-ID], got %q", args)
+	}
+	networkID, err := resolveFileNetworkID(args, *networkName, keyFormatKeyInfo)
+	if err != nil {
+		return err
+	}
+	hrp, err := resolveHRP(networkID, *hrpOverride)
+	if err != nil {
+		return err
+	}
+
+	stakingKey, err := validateFile(args[0], keyFormatKeyInfo, networkID, hrp, false)
+	if err != nil {
+		return fmt.Errorf("invalid key file %q (%w)", args[0], err)
+	}
+	fmt.Printf("staking key P-chain address: %s\n", stakingKey.PAddress)
+	if *rewardKey == "" {
+		fmt.Printf("reward address: %s\n", stakingKey.PAddress)
+		fmt.Println("the p_address of the key is usable as the reward owner (same key hash as the X/C-chain addresses)")
+		return nil
+	}
+
+	reward, err := validateFile(*rewardKey, keyFormatKeyInfo, networkID, hrp, false)
+	if err != nil {
+		return fmt.Errorf("invalid --reward-key %q (%w)", *rewardKey, err)
+	}
+	if reward.ShortAddress == stakingKey.ShortAddress {
+		return fmt.Errorf("--reward-key %q is the same key as %q (%s), the rewards are not separated", *rewardKey, args[0], reward.PAddress)
+	}
+	fmt.Printf("reward address: %s\n", reward.PAddress)
+	fmt.Printf("the rewards go to the separate key %q, not to the staking key\n", *rewardKey)
+	return nil
+}
+
+// go run main.go refresh /tmp/old.key.json 9999
+// go run main.go refresh /tmp/old.key.json 9999 --dry-run
+//
+// Rewrites the key file in place with every derived field recomputed
+// from "private_key" (e.g., after an avalanchego upgrade changes the
+// address encoding), and prints the fields that changed.
+func refresh(args []string) error {
+	fs := flag.NewFlagSet("refresh", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only print the fields that would change, without writing the key file")
+	hrpOverride := hrpFlag(fs)
+	networkName := networkFlag(fs)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 && len(args) != 2 {
+		return usageError("expected 1 or 2 args: refresh [KEY-PATH] [NETWORK-ID], got %q", args)
+	}
+	if args[0] == stdinPath {
+		return usageError("refresh requires a key info file path")
+	}
+	networkID, err := resolveFileNetworkID(args, *networkName, keyFormatKeyInfo)
+	if err != nil {
+		return err
+	}
+	hrp, err := resolveHRP(networkID, *hrpOverride)
+	if err != nil {
+		return err
+	}
+
+	b, err := readKeyFile(args[0])
+	if err != nil {
+		return err
+	}
+	ki, err := decodeKey(b, keyFormatKeyInfo, networkID, hrp)
+	if err != nil {
+		return err
+	}
+	refreshed, changed, err := keyinfo.Refresh(ki, networkID, hrp)
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		fmt.Printf("%q is up to date\n", args[0])
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tOLD\tNEW")
+	for _, f := range changed {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", f.Field, f.Stored, f.Derived)
+	}
+	tw.Flush()
+	fmt.Printf("\n%d fields changed\n", len(changed))
+	if *dryRun {
+		return nil
+	}
+
+	var ob []byte
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		ob, err = json.MarshalIndent(refreshed, "", "    ")
+	} else {
+		ob, err = yaml.Marshal(refreshed)
+	}
+	if err != nil {
+		return err
+	}
+	if ob, err = sealKeyFile(args[0], ob); err != nil {
+		return err
+	}
+	logger.Infof("saving to %q", args[0])
+	if err := ioutil.WriteFile(args[0], ob, fsModeWrite); err != nil {
+		return ioError(err)
+	}
+	return nil
+}
+
+// chainsFlag registers the "--chains" flag.
+func chainsFlag(fs *flag.FlagSet) *string {
+	return fs.String("chains", "", "comma-separated chain aliases or CB58 chain IDs to derive addresses for (e.g., X,P,C,mychain)")
+}
+
+// parseChains parses the "--chains" flag, returning nil if not set.
+func parseChains(chains string) ([]string, error) {
+	if chains == "" {
+		return nil, nil
+	}
+	aliases, err := keyinfo.ParseChainAliases(chains)
+	if err != nil {
+		return nil, usageError("invalid --chains (%v)", err)
+	}
+	return aliases, nil
+}
+
+// parseEncoders parses the "--encoder" flag into the registered address
+// encoder names, returning nil if not set.
+func parseEncoders(encoder string) ([]string, error) {
+	if encoder == "" {
+		return nil, nil
+	}
+	names := strings.Split(encoder, ",")
+	for i, name := range names {
+		names[i] = strings.ToLower(strings.TrimSpace(name))
+		if _, err := keyinfo.AddressEncoderByName(names[i]); err != nil {
+			return nil, usageError("invalid --encoder (%v)", err)
+		}
+	}
+	return names, nil
+}
+
+// encodeAddresses encodes the key's address with each address encoder,
+// keyed by the encoder name.
+func encodeAddresses(ki keyinfo.Info, encoders []string, networkID uint32) (map[string]string, error) {
+	if len(encoders) == 0 {
+		return nil, nil
+	}
+	if ki.WatchOnly {
+		return nil, errors.New("--encoder requires the private key, not a watch-only key")
+	}
+	pk, err := keyinfo.DecodePrivateKey(ki.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	encoded := make(map[string]string, len(encoders))
+	for _, name := range encoders {
+		if encoded[name], err = keyinfo.EncodeAddressWith(pk, name, networkID); err != nil {
+			return nil, err
+		}
+	}
+	return encoded, nil
+}
+
+// labelChains maps the "--labels" and "--only" chain names to the key file
+// fields of the addresses, in the key file order.
+var labelChains = []struct {
+	chain string
+	field string
+}{
+	{"x", "x_address"},
+	{"p", "p_address"},
+	{"c", "c_address"},
+	{"eth", "eth_address"},
+	{"short", "short_address"},
+}
+
+// parseLabels parses the "--labels" flag of [CHAIN]=[LABEL] pairs (the chain
+// case-insensitive), returning the labels by the key file field, or nil if not set.
+// The labels cannot have commas, which separate the pairs.
+func parseLabels(labels string) (map[string]string, error) {
+	if labels == "" {
+		return nil, nil
+	}
+	byField := make(map[string]string)
+	for _, pair := range strings.Split(labels, ",") {
+		idx := strings.Index(pair, "=")
+		if idx < 0 {
+			return nil, usageError("invalid --labels pair %q (expected [CHAIN]=[LABEL])", pair)
+		}
+		chain, label := strings.ToLower(strings.TrimSpace(pair[:idx])), strings.TrimSpace(pair[idx+1:])
+		field := chainField(chain)
+		if field == "" {
+			return nil, usageError("unknown --labels chain %q (expected x, p, c, eth, or short)", chain)
+		}
+		if label == "" {
+			return nil, usageError("empty --labels label for %q", chain)
+		}
+		if strings.IndexFunc(label, unicode.IsControl) >= 0 {
+			return nil, usageError("invalid --labels label %q for %q (has control characters)", label, chain)
+		}
+		if _, dup := byField[field]; dup {
+			return nil, usageError("duplicate --labels chain %q", chain)
+		}
+		byField[field] = label
+	}
+	return byField, nil
+}
+
+// chainField returns the key file field of the address of the chain name
+// (lowercase), or "" if unknown.
+func chainField(chain string) string {
+	for _, c := range labelChains {
+		if c.chain == chain {
+			return c.field
+		}
+	}
+	return ""
+}
+
+// fieldAddress returns the address of the key file field (see labelChains).
+func fieldAddress(ki keyinfo.Info, field string) string {
+	switch field {
+	case "x_address":
+		return ki.XAddress
+	case "p_address":
+		return ki.PAddress
+	case "c_address":
+		return ki.CAddress
+	case "eth_address":
+		return ki.EthAddress
+	case "short_address":
+		return ki.ShortAddress
+	}
+	return ""
+}
+
+// parseOnly parses the "--only" chain name (case-insensitive), returning
+// the key file field of its address, or "" if not set.
+func parseOnly(only string) (string, error) {
+	if only == "" {
+		return "", nil
+	}
+	field := chainField(strings.ToLower(strings.TrimSpace(only)))
+	if field == "" {
+		return "", usageError("unknown --only chain %q (expected x, p, c, eth, or short)", only)
+	}
+	return field, nil
+}
+
+// labelAddresses returns the labeled addresses of the key, in the key file
+// order. The addresses without a label are left out.
+func labelAddresses(ki keyinfo.Info, labels map[string]string) []addressLabel {
+	var labeled []addressLabel
+	for _, c := range labelChains {
+		if label, ok := labels[c.field]; ok {
+			labeled = append(labeled, addressLabel{Field: c.field, Address: fieldAddress(ki, c.field), Label: label})
+		}
+	}
+	return labeled
+}
+
+// networksFlag registers the "--networks" flag.
+func networksFlag(fs *flag.FlagSet) *string {
+	return fs.String("networks", "", "comma-separated network IDs to derive addresses for (e.g., 1,5,9999)")
+}
+
+// parseNetworks parses the "--networks" flag, returning nil if not set.
+func parseNetworks(networks string) ([]uint32, error) {
+	if networks == "" {
+		return nil, nil
+	}
+	var networkIDs []uint32
+	seen := make(map[uint32]bool)
+	for _, s := range strings.Split(networks, ",") {
+		networkID, err := parseNetworkID(strings.TrimSpace(s))
+		if err != nil {
+			return nil, usageError("invalid --networks (%v)", err)
+		}
+		if seen[networkID] {
+			return nil, usageError("invalid --networks (duplicate network ID %d)", networkID)
+		}
+		seen[networkID] = true
+		networkIDs = append(networkIDs, networkID)
+	}
+	// the same set of networks always writes the same key file bytes,
+	// regardless of the flag order (e.g., for the key files in git)
+	sort.Slice(networkIDs, func(i, j int) bool { return networkIDs[i] < networkIDs[j] })
+	return networkIDs, nil
+}
+
+// assertFlags are the expected addresses to pin in CI
+// (e.g., so the funding addresses never change unnoticed).
+type assertFlags struct {
+	x   *string
+	p   *string
+	c   *string
+	eth *string
+}
+
+// registerAssertFlags registers the "--assert-x", "--assert-p",
+// "--assert-c", and "--assert-eth" flags.
+func registerAssertFlags(fs *flag.FlagSet) assertFlags {
+	return assertFlags{
+		x:   fs.String("assert-x", "", "fail unless the derived X-chain address is this address"),
+		p:   fs.String("assert-p", "", "fail unless the derived P-chain address is this address"),
+		c:   fs.String("assert-c", "", "fail unless the derived C-chain address is this address"),
+		eth: fs.String("assert-eth", "", "fail unless the derived eth address is this address (with the EIP-55 checksum casing)"),
+	}
+}
+
+// check compares each set expected address with the derived address,
+// and reports all the mismatches.
+func (f assertFlags) check(ki keyinfo.Info) error {
+	var failed []string
+	for _, a := range []struct {
+		flag     string
+		expected string
+		actual   string
+	}{
+		{"--assert-x", *f.x, ki.XAddress},
+		{"--assert-p", *f.p, ki.PAddress},
+		{"--assert-c", *f.c, ki.CAddress},
+		{"--assert-eth", *f.eth, ki.EthAddress},
+	} {
+		if a.expected != "" && a.expected != a.actual {
+			failed = append(failed, fmt.Sprintf("%s expected %q, actual %q", a.flag, a.expected, a.actual))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("address assertion failed: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// qrFlags are the flags to render the QR code of an address
+// (e.g., to fund the key from a mobile wallet).
+type qrFlags struct {
+	qr    *bool
+	chain *string
+	out   *string
+}
+
+// registerQRFlags registers the "--qr", "--qr-chain", and "--qr-out" flags.
+func registerQRFlags(fs *flag.FlagSet) qrFlags {
+	return qrFlags{
+		qr:    fs.Bool("qr", false, "print the QR code of the --qr-chain address to the terminal"),
+		chain: fs.String("qr-chain", "X", "address to render as the QR code (X, P, C, eth)"),
+		out:   fs.String("qr-out", "", "PNG file to write the QR code to"),
+	}
+}
+
+// check validates the "--qr-chain" flag.
+func (f qrFlags) check() error {
+	switch *f.chain {
+	case "X", "P", "C", "eth":
+		return nil
+	}
+	return usageError("unknown --qr-chain %q (expected X, P, C, or eth)", *f.chain)
+}
+
+// render prints the QR code if "--qr" is set, unless quiet,
+// and writes the PNG if "--qr-out" is set.
+func (f qrFlags) render(ki keyinfo.Info, quiet bool) error {
+	if !*f.qr && *f.out == "" {
+		return nil
+	}
+	addr := map[string]string{
+		"X":   ki.XAddress,
+		"P":   ki.PAddress,
+		"C":   ki.CAddress,
+		"eth": ki.EthAddress,
+	}[*f.chain]
+	q, err := qrcode.New(addr, qrcode.Medium)
+	if err != nil {
+		return err
+	}
+	if *f.qr && !quiet {
+		fmt.Println(addr)
+		fmt.Print(q.ToSmallString(false))
+	}
+	if *f.out != "" {
+		b, err := q.PNG(qrPNGSize)
+		if err != nil {
+			return err
+		}
+		logger.Infof("saving QR code to %q", *f.out)
+		if err := ioutil.WriteFile(*f.out, b, fsModeWrite); err != nil {
+			return ioError(err)
+		}
+	}
+	return nil
+}
+
+// qrPNGSize is the width and height of the QR code PNG in pixels.
+const qrPNGSize = 256
+
+// fundingHelpFlag registers the "--funding-help" flag.
+func fundingHelpFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("funding-help", false, "print how to fund the key on the network (faucet, and the address to paste for each chain)")
+}
+
+// includePubkeyFlag registers the "--include-pubkey" flag.
+func includePubkeyFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("include-pubkey", false, "include the compressed and uncompressed public keys")
+}
+
+// addPublicKeys adds the public keys to the key info if "--include-pubkey" is set.
+func addPublicKeys(ki *keyinfo.Info, include bool) error {
+	if !include {
+		return nil
+	}
+	pk, err := keyinfo.DecodePrivateKey(ki.PrivateKey)
+	if err != nil {
+		return err
+	}
+	ki.PublicKeyCompressed, ki.PublicKeyUncompressed, err = keyinfo.EncodePublicKeys(pk)
+	return err
+}
+
+// strictNetworkFlag registers the "--strict-network" flag.
+func strictNetworkFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("strict-network", false, "fail on network IDs that are not standard Avalanche networks (e.g., 99999 instead of 9999)")
+}
+
+// checkNetworkID fails on the custom network IDs if "--strict-network" is set.
+func checkNetworkID(networkID uint32, strict bool) error {
+	if strict && !keyinfo.IsStandardNetwork(networkID) {
+		return usageError("unrecognized network ID %d with --strict-network (expected one of %s)", networkID, keyinfo.StandardNetworks())
+	}
+	return nil
+}
+
+// warnNetworkHRP warns if the HRP is not the one reserved for the standard network.
+func warnNetworkHRP(networkID uint32, hrp string) {
+	if err := keyinfo.CheckNetworkHRP(networkID, hrp); err != nil {
+		logger.Warnf("%v", err)
+	}
+}
+
+// hrpFlag registers the "--hrp" flag.
+func hrpFlag(fs *flag.FlagSet) *string {
+	return fs.String("hrp", "", "bech32 HRP to use instead of the network ID's (e.g., for private deployments)")
+}
+
+// resolveHRP returns the "--hrp" override if set,
+// and the HRP of the network ID otherwise.
+func resolveHRP(networkID uint32, hrp string) (string, error) {
+	if hrp == "" {
+		return constants.GetHRP(networkID), nil
+	}
+	if err := keyinfo.ValidateHRP(hrp); err != nil {
+		return "", usageError("invalid --hrp (%v)", err)
+	}
+	return hrp, nil
+}
+
+// addAddresses derives the addresses for the "--chains" aliases
+// and the "--networks" network IDs, if any.
+func addAddresses(ki *keyinfo.Info, aliases []string, networkIDs []uint32, hrp string) error {
+	if len(aliases) == 0 && len(networkIDs) == 0 {
+		return nil
+	}
+	pk, err := keyinfo.DecodePrivateKey(ki.PrivateKey)
+	if err != nil {
+		return err
+	}
+	if len(aliases) > 0 {
+		ki.Addresses, err = keyinfo.EncodeAddrs(pk, aliases, hrp)
+		if err != nil {
+			return err
+		}
+	}
+	if len(networkIDs) > 0 {
+		ki.Networks, err = keyinfo.EncodeNetworksAddrs(pk, networkIDs, aliases)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// marshalInfo encodes the key info in JSON if the path has ".json" extension,
+// and in YAML otherwise. Both are stable for the same key info, since
+// encoding/json sorts the map keys (e.g., "addresses"), and the YAML is
+// converted from the JSON.
+func marshalInfo(ki keyinfo.Info, fpath string) ([]byte, error) {
+	if filepath.Ext(fpath) == ".json" {
+		return json.MarshalIndent(ki, "", "    ")
+	}
+	return yaml.Marshal(ki)
+}
+
+// Chain address output styles of "--address-style".
+const (
+	addressStyleFull       = "full"
+	addressStyleBech32Only = "bech32-only"
+	addressStyleHashHex    = "hash-hex"
+)
+
+// styleAddresses rewrites the chain addresses of the key info for output
+// in the address style, after the key info is validated with the full
+// addresses. The eth and short addresses are kept as is.
+func styleAddresses(ki *keyinfo.Info, style string) error {
+	if style == addressStyleFull {
+		return nil
+	}
+	var err error
+	restyle := func(addr string) string {
+		if err != nil || addr == "" {
+			return addr
+		}
+		var styled string
+		styled, err = styleAddress(addr, style)
+		return styled
+	}
+	ki.XAddress, ki.PAddress, ki.CAddress = restyle(ki.XAddress), restyle(ki.PAddress), restyle(ki.CAddress)
+	ki.Addresses = restyleMap(ki.Addresses, restyle)
+	if ki.Networks != nil {
+		networks := make([]keyinfo.NetworkAddresses, len(ki.Networks))
+		for i, n := range ki.Networks {
+			n.XAddress, n.PAddress, n.CAddress = restyle(n.XAddress), restyle(n.PAddress), restyle(n.CAddress)
+			n.Addresses = restyleMap(n.Addresses, restyle)
+			networks[i] = n
+		}
+		ki.Networks = networks
+	}
+	return err
+}
+
+// restyleMap returns a copy of the chain addresses, so the styled key info
+// does not share the map with the validated one.
+func restyleMap(addrs map[string]string, restyle func(string) string) map[string]string {
+	if addrs == nil {
+		return nil
+	}
+	styled := make(map[string]string, len(addrs))
+	for alias, addr := range addrs {
+		styled[alias] = restyle(addr)
+	}
+	return styled
+}
+
+// styleAddress returns the bech32 part without the chain alias
+// (e.g., "custom1..."), or the "0x"-prefixed 20-byte hash in hex.
+func styleAddress(addr string, style string) (string, error) {
+	_, _, b, err := formatting.ParseAddress(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid chain address %q (%v)", addr, err)
+	}
+	if style == addressStyleHashHex {
+		return "0x" + hex.EncodeToString(b), nil
+	}
+	return addr[strings.Index(addr, "-")+1:], nil
+}
+
+// marshalJSON encodes the printed JSON output, indented by default
+// or on a single line if compact (e.g., for jq or log lines).
+func marshalJSON(v interface{}, compact bool) ([]byte, error) {
+	if compact {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", "    ")
+}
+
+// go run main.go validate-dir /tmp/keys 9999
+// go run main.go validate-dir /tmp/keys 9999 --workers 16
+// go run main.go validate-dir /tmp/keys 9999 --timeout 30s
+// go run main.go validate-dir /tmp/keys 9999 --quiet
+// go run main.go validate-dir /tmp/keys 9999 --format csv > /tmp/keys.csv
+// go run main.go validate-dir /tmp/keys --network fuji
+// go run main.go validate-dir /tmp/keys 9999 --check-duplicates
+//
+// The files are validated in parallel, and reported in the lexical order
+// of the paths, so the report is the same for any --workers.
+func validateDir(args []string) error {
+	fs := flag.NewFlagSet("validate-dir", flag.ContinueOnError)
+	format := fs.String("format", "text", "output format (text, csv)")
+	noHeader := noHeaderFlag(fs)
+	workers := fs.Int("workers", runtime.NumCPU(), "number of files to validate in parallel")
+	timeout := timeoutFlag(fs)
+	quiet := fs.Bool("quiet", false, "do not report the progress to stderr, and only log errors")
+	checkDuplicates := fs.Bool("check-duplicates", false, "fail if any two files share the same private key or X-chain address")
+	hrpOverride := hrpFlag(fs)
+	strictNetwork := strictNetworkFlag(fs)
+	strict := strictFlag(fs)
+	fields := fieldsFlag(fs)
+	networkName := networkFlag(fs)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 && len(args) != 2 {
+		return usageError("expected 1 or 2 args: validate-dir [DIR-PATH] [NETWORK-ID], got %q", args)
+	}
+	if err := parseFields(*fields); err != nil {
+		return err
+	}
+	if *workers < 1 {
+		return usageError("invalid --workers %d", *workers)
+	}
+	if *timeout < 0 {
+		return usageError("invalid --timeout %s", *timeout)
+	}
+	if *format != "text" && *format != "csv" {
+		return usageError("unknown --format %q (expected text or csv)", *format)
+	}
+	if *quiet && logger.level < logLevelError {
+		logger.level = logLevelError
+	}
+
+	dir := args[0]
+	networkID, err := resolveNetwork(*networkName, optionalArg(args, 1))
+	if err != nil {
+		return err
+	}
+	if err := checkNetworkID(networkID, *strictNetwork); err != nil {
+		return err
+	}
+	hrp, err := resolveHRP(networkID, *hrpOverride)
+	if err != nil {
+		return err
+	}
+	warnNetworkHRP(networkID, hrp)
+
+	var fpaths []string
+	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(info.Name(), keyFileSuffix) {
+			fpaths = append(fpaths, path)
+		}
+		return nil
+	}); err != nil {
+		return ioError(err)
+	}
+	// the results are reported in the lexical order of the paths,
+	// whichever worker finishes first
+	sort.Strings(fpaths)
+	logger.Infof("validating %d files in %q for network %s with HRP %q and %d workers", len(fpaths), dir, keyinfo.NetworkLabel(networkID), hrp, *workers)
+
+	ctx, cancel := timeoutContext(*timeout)
+	defer cancel()
+	prog := startProgress("validated", "files", len(fpaths), !*quiet && isTerminal(os.Stderr))
+	fpathc := make(chan string)
+	resultc := make(chan validateResult)
+	var wg sync.WaitGroup
+	for i := 0; i < *workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for fpath := range fpathc {
+				ki, err := validateFile(fpath, keyFormatKeyInfo, networkID, hrp, *strict)
+				prog.add(err != nil)
+				resultc <- validateResult{name: fpath, ki: ki, err: err}
+			}
+		}()
+	}
+	// once the context is done, no more files are started,
+	// and the files in flight are still reported
+	var started int
+	go func() {
+		defer func() {
+			close(fpathc)
+			wg.Wait()
+			// the progress line is done before the results are flushed to stdout
+			prog.stop()
+			close(resultc)
+		}()
+		for _, fpath := range fpaths {
+			select {
+			case fpathc <- fpath:
+				started++
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	results := orderResults(resultc, fpaths)
+	var validated []validateResult
+	if *checkDuplicates {
+		results = collectResults(results, &validated)
+	}
+	if *format == "csv" {
+		err = writeResultsCSV(results, "file", *noHeader, networkID, len(fpaths))
+	} else {
+		err = writeResultsTable(results, "FILE", len(fpaths))
+	}
+	if *checkDuplicates {
+		if derr := reportDuplicates(validated); derr != nil {
+			if err != nil {
+				logger.Errorf("%v", err)
+			}
+			err = derr
+		}
+	}
+	// resultc is closed after the last send, so "started" is final
+	if started < len(fpaths) {
+		if err != nil {
+			logger.Errorf("%v", err)
+		}
+		return timeoutError("timed out (%v), validated %d of %d files", ctx.Err(), started, len(fpaths))
+	}
+	return err
+}
+
+// collectResults forwards the results, and appends each of them to the slice,
+// which is complete once the returned channel is closed.
+func collectResults(resultc <-chan validateResult, collected *[]validateResult) <-chan validateResult {
+	out := make(chan validateResult)
+	go func() {
+		defer close(out)
+		for res := range resultc {
+			*collected = append(*collected, res)
+			out <- res
+		}
+	}()
+	return out
+}
+
+// orderResults forwards the results in the order of the names, instead of
+// the completion order, so the report is the same on every run regardless of
+// the workers. The names are sent to the workers in the same order, so only
+// the results that finish before an earlier one are held back.
+func orderResults(resultc <-chan validateResult, names []string) <-chan validateResult {
+	out := make(chan validateResult)
+	go func() {
+		defer close(out)
+		pending := make(map[string]validateResult)
+		next := 0
+		for res := range resultc {
+			pending[res.name] = res
+			for next < len(names) {
+				res, ok := pending[names[next]]
+				if !ok {
+					break
+				}
+				delete(pending, names[next])
+				out <- res
+				next++
+			}
+		}
+	}()
+	return out
+}
+
+// reportDuplicates logs each pair of the validated keys that are the same key,
+// and fails if there is any. The results are in the order of the names, so the
+// same pairs are reported on every run.
+// The keys that failed validation are already reported, and skipped.
+func reportDuplicates(results []validateResult) error {
+	idx := keyinfo.NewDuplicateIndex()
+	duplicates := 0
+	for _, res := range results {
+		if res.err != nil {
+			continue
+		}
+		dup, ok, err := idx.Add(res.name, res.ki)
+		if err != nil {
+			return fmt.Errorf("%s: %v", res.name, err)
+		}
+		if ok {
+			logger.Errorf("DUPLICATE %s and %s (%s)", dup.First, dup.Second, dup.Reason)
+			duplicates++
+		}
+	}
+	if duplicates > 0 {
+		return fmt.Errorf("%d of %d keys are the same key as another file", duplicates, len(results))
+	}
+	return nil
+}
+
+// progressInterval is how often the progress line is redrawn.
+const progressInterval = 200 * time.Millisecond
+
+// progress reports the processed and total counts of a batch run on a single
+// stderr line, so it never mixes with the results on stdout. The workers
+// update the counters atomically, and only the reporter goroutine writes.
+type progress struct {
+	// processed and failed are updated with sync/atomic, and come first
+	// for the 64-bit alignment on 32-bit platforms.
+	processed int64
+	failed    int64
+
+	verb  string
+	noun  string
+	total int
+	// done is nil if the progress is not reported.
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// startProgress starts reporting the progress to stderr if enabled
+// (e.g., not with --quiet, and only if stderr is a terminal).
+// The counters are always kept, so the disabled progress is still safe to use.
+func startProgress(verb string, noun string, total int, enabled bool) *progress {
+	p := &progress{verb: verb, noun: noun, total: total}
+	if !enabled || total == 0 {
+		return p
+	}
+	p.done, p.stopped = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(p.stopped)
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.print()
+			case <-p.done:
+				p.print()
+				fmt.Fprintln(os.Stderr)
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// add counts one processed item, safe to call from any goroutine.
+func (p *progress) add(failed bool) {
+	atomic.AddInt64(&p.processed, 1)
+	if failed {
+		atomic.AddInt64(&p.failed, 1)
+	}
+}
+
+// stop prints the final counts, and waits for the reporter to exit.
+func (p *progress) stop() {
+	if p.done == nil {
+		return
+	}
+	close(p.done)
+	<-p.stopped
+}
+
+func (p *progress) print() {
+	processed, failed := atomic.LoadInt64(&p.processed), atomic.LoadInt64(&p.failed)
+	fmt.Fprintf(os.Stderr, "\r%s %d/%d %s (%d%%), %d failed", p.verb, processed, p.total, p.noun, processed*100/int64(p.total), failed)
+}
+
+// isTerminal returns true if the file is a terminal (character device),
+// not a pipe or a regular file.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// timeoutFlag registers the "--timeout" flag for the batch modes.
+func timeoutFlag(fs *flag.FlagSet) *time.Duration {
+	return fs.Duration("timeout", 0, "stop starting new work after the duration and report the partial results (0 for no timeout)")
+}
+
+// timeoutContext returns the context that is done after the timeout,
+// or only when canceled if the timeout is zero.
+func timeoutContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+const keyFileSuffix = ".key.json"
+
+type validateResult struct {
+	// name is the key file path, or the index in the key array file.
+	name string
+	ki   keyinfo.Info
+	err  error
+}
+
+// writeResultsTable prints the PASS or FAIL result of each key,
+// and fails if any key failed.
+func writeResultsTable(resultc <-chan validateResult, column string, total int) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tX-ADDRESS\tRESULT\n", column)
+	succeeded, failed := 0, 0
+	for res := range resultc {
+		result := "PASS"
+		if res.err != nil {
+			result = fmt.Sprintf("FAIL (%v)", res.err)
+			failed++
+		} else {
+			succeeded++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", res.name, res.ki.XAddress, result)
+	}
+	tw.Flush()
+
+	fmt.Printf("\n%d succeeded, %d failed\n", succeeded, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d keys failed validation", failed, total)
+	}
+	return nil
+}
+
+// writeResultsCSV writes one CSV row per valid key, and logs the failed keys.
+func writeResultsCSV(resultc <-chan validateResult, column string, noHeader bool, networkID uint32, total int) error {
+	cw := csv.NewWriter(os.Stdout)
+	if !noHeader {
+		cw.Write(append([]string{column}, csvHeader...))
+	}
+	failed := 0
+	for res := range resultc {
+		if res.err != nil {
+			logger.Errorf("FAIL %s (%v)", res.name, res.err)
+			failed++
+			continue
+		}
+		cw.Write(append([]string{res.name}, csvRecord(res.ki, networkID)...))
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return ioError(err)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d keys failed validation", failed, total)
+	}
+	return nil
+}
+
+// go run main.go validate-array /tmp/keys.json 9999
+// go run main.go validate-array /tmp/keys.json 9999 --format csv > /tmp/keys.csv
+// cat /tmp/keys.json | go run main.go validate-array - --network fuji
+//
+// The key array file is a JSON array of key info objects, same as the
+// key files of "validate-dir" in a single file. Each element is reported
+// by its index, and any invalid element fails the whole file.
+func validateArray(args []string) error {
+	fs := flag.NewFlagSet("validate-array", flag.ContinueOnError)
+	format := fs.String("format", "text", "output format (text, csv)")
+	noHeader := noHeaderFlag(fs)
+	hrpOverride := hrpFlag(fs)
+	strictNetwork := strictNetworkFlag(fs)
+	strict := strictFlag(fs)
+	networkName := networkFlag(fs)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 && len(args) != 2 {
+		return usageError("expected 1 or 2 args: validate-array [KEYS-PATH] [NETWORK-ID], got %q", args)
+	}
+	if *format != "text" && *format != "csv" {
+		return usageError("unknown --format %q (expected text or csv)", *format)
+	}
+
+	networkID, err := resolveNetwork(*networkName, optionalArg(args, 1))
+	if err != nil {
+		return err
+	}
+	if err := checkNetworkID(networkID, *strictNetwork); err != nil {
+		return err
+	}
+	hrp, err := resolveHRP(networkID, *hrpOverride)
+	if err != nil {
+		return err
+	}
+	warnNetworkHRP(networkID, hrp)
+
+	b, err := readKeyFile(args[0])
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(b), []byte("[")) {
+		return fmt.Errorf("%q is not a JSON array of key info objects (use validate for a single key file)", args[0])
+	}
+	var elems []json.RawMessage
+	if err := json.Unmarshal(b, &elems); err != nil {
+		return fmt.Errorf("invalid key array file %q (%v)", args[0], err)
+	}
+	logger.Infof("validating %d keys in %q for network %s with HRP %q", len(elems), args[0], keyinfo.NetworkLabel(networkID), hrp)
+
+	resultc := make(chan validateResult, len(elems))
+	for i, elem := range elems {
+		res := validateResult{name: strconv.Itoa(i)}
+		if bytes.HasPrefix(bytes.TrimSpace(elem), []byte("{")) {
+			res.ki, res.err = decodeKey(elem, keyFormatKeyInfo, networkID, hrp)
+			if res.err == nil {
+				res.err = validateKey(res.ki, networkID, hrp, *strict)
+			}
+		} else {
+			res.err = errors.New("not a key info object")
+		}
+		resultc <- res
+	}
+	close(resultc)
+
+	if *format == "csv" {
+		return writeResultsCSV(resultc, "index", *noHeader, networkID, len(elems))
+	}
+	return writeResultsTable(resultc, "INDEX", len(elems))
+}
+
+// multipleKeyFilesFlags are the validate flags that apply to each of the
+// multiple key files, and the other flags are for a single key file.
+var multipleKeyFilesFlags = map[string]bool{
+	"format":         true,
+	"no-header":      true,
+	"quiet":          true,
+	"key-format":     true,
+	"hrp":            true,
+	"strict":         true,
+	"fields":         true,
+	"strict-network": true,
+	"network":        true,
+}
+
+// multipleKeyFiles returns the key file paths and the [NETWORK-ID] arg,
+// if the args are more than one key file: [KEY-PATH]... [NETWORK-ID],
+// or [KEY-PATH]... with --network (the last arg is the network ID if it is
+// a number). A single key file is [KEY-PATH] [NETWORK-ID] as before.
+func multipleKeyFiles(args []string, networkName string) ([]string, string, bool) {
+	if len(args) < 2 {
+		return nil, "", false
+	}
+	last := args[len(args)-1]
+	_, err := parseNetworkID(last)
+	switch {
+	case err == nil && len(args) >= 3:
+		return args[:len(args)-1], last, true
+	case err != nil && networkName != "":
+		return args, "", true
+	}
+	return nil, "", false
+}
+
+// go run main.go validate a.key.json b.key.json c.key.json 9999
+// go run main.go validate a.key.json b.key.json --network fuji --format csv
+//
+// validateFiles validates each key file in order, and prints the PASS or FAIL
+// result of each and the summary, same as validate-dir. Any invalid key
+// file fails the run.
+func validateFiles(fpaths []string, networkArg string, networkName string, format string, noHeader bool, keyFormat string, hrpOverride string, strictNetwork bool, strict bool) error {
+	networkID, err := resolveNetwork(networkName, networkArg)
+	if err != nil {
+		return err
+	}
+	if err := checkNetworkID(networkID, strictNetwork); err != nil {
+		return err
+	}
+	hrp, err := resolveHRP(networkID, hrpOverride)
+	if err != nil {
+		return err
+	}
+	warnNetworkHRP(networkID, hrp)
+	logger.Infof("validating %d files for network %s with HRP %q", len(fpaths), keyinfo.NetworkLabel(networkID), hrp)
+
+	resultc := make(chan validateResult)
+	go func() {
+		defer close(resultc)
+		for _, fpath := range fpaths {
+			ki, err := validateFile(fpath, keyFormat, networkID, hrp, strict)
+			resultc <- validateResult{name: fpath, ki: ki, err: err}
+		}
+	}()
+	if format == "csv" {
+		return writeResultsCSV(resultc, "file", noHeader, networkID, len(fpaths))
+	}
+	return writeResultsTable(resultc, "FILE", len(fpaths))
+}
+
+// envNamePattern matches the valid shell variable names (and name prefixes).
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// envExports returns the "--format env" output, one "export" line per field
+// to "eval" in a shell. The private keys are only exported with --show-secret.
+func envExports(ki keyinfo.Info, networkID uint32, hrp string, prefix string, showSecret bool) string {
+	names := []string{"NETWORK_ID", "HRP", "X_ADDRESS", "P_ADDRESS", "C_ADDRESS", "ETH_ADDRESS", "SHORT_ADDRESS"}
+	values := []string{strconv.FormatUint(uint64(networkID), 10), hrp, ki.XAddress, ki.PAddress, ki.CAddress, ki.EthAddress, ki.ShortAddress}
+	if showSecret {
+		names = append(names, "PRIVATE_KEY", "PRIVATE_KEY_HEX")
+		values = append(values, ki.PrivateKey, ki.PrivateKeyHex)
+	}
+	var sb strings.Builder
+	for i, name := range names {
+		fmt.Fprintf(&sb, "export %s%s=%s\n", prefix, name, shellQuote(values[i]))
+	}
+	return sb.String()
+}
+
+// shellQuote single-quotes the value for the POSIX shells, so nothing in it
+// is expanded (e.g., "$" or backticks), and each single quote is closed,
+// escaped, and reopened.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// defaultHCLVariable is the default "--format hcl" variable name.
+const defaultHCLVariable = "avalanche_key"
+
+// hclNamePattern matches the valid HCL identifiers (e.g., Terraform variable names).
+var hclNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// hclVariableMap returns the "--format hcl" output, the Terraform variable
+// (e.g., in a .tfvars file) of the map of the addresses by field name, same as
+// the key info file fields. The private keys are only included with --show-secret.
+func hclVariableMap(ki keyinfo.Info, networkID uint32, hrp string, name string, showSecret bool) string {
+	names := []string{"network_id", "hrp", "x_address", "p_address", "c_address", "eth_address", "short_address"}
+	values := []string{strconv.FormatUint(uint64(networkID), 10), hclQuote(hrp), hclQuote(ki.XAddress), hclQuote(ki.PAddress), hclQuote(ki.CAddress), hclQuote(ki.EthAddress), hclQuote(ki.ShortAddress)}
+	if showSecret {
+		names = append(names, "private_key", "private_key_hex")
+		values = append(values, hclQuote(ki.PrivateKey), hclQuote(ki.PrivateKeyHex))
+	}
+	width := 0
+	for _, n := range names {
+		if len(n) > width {
+			width = len(n)
+		}
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s = {\n", name)
+	for i, n := range names {
+		// aligned the same as "terraform fmt"
+		fmt.Fprintf(&sb, "  %-*s = %s\n", width, n, values[i])
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// hclQuote double-quotes the value as an HCL string literal, escaping the
+// quotes, backslashes, and control characters, and the "${" and "%{"
+// template sequences, so the value is never interpolated.
+func hclQuote(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i, r := range s {
+		switch {
+		case r == '"':
+			sb.WriteString(`\"`)
+		case r == '\\':
+			sb.WriteString(`\\`)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&sb, `\u%04X`, r)
+		case (r == '$' || r == '%') && strings.HasPrefix(s[i+1:], "{"):
+			// "$${" and "%%{" are the literal "${" and "%{"
+			sb.WriteRune(r)
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// csvHeader is the header row of the "--format csv" output.
+var csvHeader = []string{"network_id", "x_address", "p_address", "c_address", "eth_address", "short_address"}
+
+// csvRecord returns the "--format csv" row of the key info.
+func csvRecord(ki keyinfo.Info, networkID uint32) []string {
+	return []string{
+		strconv.FormatUint(uint64(networkID), 10),
+		ki.XAddress,
+		ki.PAddress,
+		ki.CAddress,
+		ki.EthAddress,
+		ki.ShortAddress,
+	}
+}
+
+func noHeaderFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("no-header", false, "omit the header row of the \"--format csv\" output")
+}
+
+// go run main.go verify-address ../../artifacts/ewoq.key.json X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p
+// go run main.go verify-address ../../artifacts/ewoq.key.json P-avax18jma8ppw3nhx5r4ap8clazz0dps7rv5ukulre5
+func verifyAddress(args []string) error {
+	fs := flag.NewFlagSet("verify-address", flag.ContinueOnError)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return usageError("expected 2 args: verify-address [KEY-PATH] [ADDRESS], got %q", args)
+	}
+	expected := args[1]
+
+	ki, err := loadKeyFile(args[0])
+	if err != nil {
+		return err
+	}
+	pk, err := keyinfo.DecodePrivateKey(ki.PrivateKey)
+	if err != nil {
+		return err
+	}
+	derived, err := keyinfo.EncodeAddrFor(pk, expected)
+	if err != nil {
+		return usageError("%v", err)
+	}
+	if derived != expected {
+		fmt.Printf("expected: %s\n", expected)
+		fmt.Printf("derived:  %s\n", derived)
+		return fmt.Errorf("address %q does not belong to the key", expected)
+	}
+
+	fmt.Println("SUCCESS")
+	return nil
+}
+
+// ewoqPrivateKeyHex is the ewoq private key in hex, which is decoded
+// whatever the "--key-prefix" of the run.
+const ewoqPrivateKeyHex = "56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027"
+
+// ewoqXAddressCustom and ewoqEthAddress are the pinned ewoq addresses
+// on the custom networks (same as "artifacts/ewoq.key.json").
+const (
+	ewoqXAddressCustom = "X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p"
+	ewoqEthAddress     = "0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC"
+)
+
+// doctorCheck is a named invariant of the doctor mode.
+type doctorCheck struct {
+	name  string
+	check func() error
+}
+
+// run runs the check, reporting a panic (e.g., in a dependency) as its failure.
+func (c doctorCheck) run() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return c.check()
+}
+
+// go run main.go doctor
+//
+// Runs the internal invariants on a freshly generated key and the pinned
+// ewoq key, reusing the same primitives as the other modes, and prints
+// a checklist. A failure means the build (e.g., the avalanchego or go-ethereum
+// versions) derives different keys or addresses than expected.
+func doctor(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return usageError("expected no args: doctor, got %q", args)
+	}
+	pk, err := keyinfo.NewPrivateKey()
+	if err != nil {
+		return err
+	}
+	skBytes := pk.Bytes()
+	sameKey := func(decoded *crypto.PrivateKeySECP256K1R, err error) error {
+		if err != nil {
+			return err
+		}
+		if !keyinfo.SecretEqual(decoded.Bytes(), skBytes) {
+			return errors.New("decoded a different private key")
+		}
+		return nil
+	}
+	msg := []byte("avalanche-ops doctor")
+
+	checks := []doctorCheck{
+		{"ewoq key derives the pinned addresses", func() error {
+			ewoq, err := keyinfo.DecodePrivateKeyFromHex(ewoqPrivateKeyHex)
+			if err != nil {
+				return err
+			}
+			ki, err := keyinfo.NewInfoFromPrivateKey(ewoq, 9999)
+			if err != nil {
+				return err
+			}
+			if ki.XAddress != ewoqXAddressCustom || ki.EthAddress != ewoqEthAddress {
+				return fmt.Errorf("derived %s and %s, expected %s and %s", ki.XAddress, ki.EthAddress, ewoqXAddressCustom, ewoqEthAddress)
+			}
+			return nil
+		}},
+		{"CB58 private key round-trip", func() error {
+			enc, err := keyinfo.EncodePrivateKey(pk)
+			if err != nil {
+				return err
+			}
+			return sameKey(keyinfo.DecodePrivateKey(enc))
+		}},
+		{"hex private key round-trip", func() error {
+			return sameKey(keyinfo.DecodePrivateKeyFromHex(hex.EncodeToString(skBytes)))
+		}},
+		{"base64 private key round-trip", func() error {
+			return sameKey(keyinfo.DecodePrivateKeyFromBase64(base64.StdEncoding.EncodeToString(skBytes)))
+		}},
+		{"SEC1 and PKCS#8 PEM round-trip", func() error {
+			for _, pkcs8 := range []bool{false, true} {
+				b, err := keyinfo.EncodePEM(pk, pkcs8)
+				if err != nil {
+					return err
+				}
+				if err := sameKey(keyinfo.DecodePEM(b)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"V3 keystore round-trip", func() error {
+			b, err := keyinfo.EncryptKeystore(pk, "doctor")
+			if err != nil {
+				return err
+			}
+			return sameKey(keyinfo.DecryptKeystore(b, "doctor"))
+		}},
+		{"encrypted key file round-trip", func() error {
+			b, err := keyinfo.EncryptFile(skBytes, "doctor")
+			if err != nil {
+				return err
+			}
+			pt, err := keyinfo.DecryptFile(b, "doctor")
+			if err != nil {
+				return err
+			}
+			if !keyinfo.SecretEqual(pt, skBytes) {
+				return errors.New("decrypted different contents")
+			}
+			if _, err := keyinfo.DecryptFile(b, "wrong"); !errors.Is(err, keyinfo.ErrDecryptFailed) {
+				return fmt.Errorf("decrypted with the wrong passphrase (%v)", err)
+			}
+			return nil
+		}},
+		{"addresses on mainnet, fuji, local, and custom re-parse and validate", func() error {
+			short := pk.PublicKey().Address().Bytes()
+			for _, networkID := range []uint32{constants.MainnetID, constants.FujiID, constants.LocalID, 9999} {
+				ki, err := keyinfo.NewInfoFromPrivateKey(pk, networkID)
+				if err != nil {
+					return err
+				}
+				if err := keyinfo.ValidateStrict(ki, networkID, constants.GetHRP(networkID)); err != nil {
+					return fmt.Errorf("network %d: %w", networkID, err)
+				}
+				for _, addr := range []string{ki.XAddress, ki.PAddress, ki.CAddress, ki.ShortAddress} {
+					ai, err := keyinfo.IdentifyAddress(addr)
+					if err != nil {
+						return err
+					}
+					if !bytes.Equal(ai.Hash, short) {
+						return fmt.Errorf("%s does not re-parse to the public key hash", addr)
+					}
+					if ai.Type == keyinfo.AddressTypeChain && ai.HRP != constants.GetHRP(networkID) {
+						return fmt.Errorf("%s has HRP %q, expected %q", addr, ai.HRP, constants.GetHRP(networkID))
+					}
+				}
+			}
+			return nil
+		}},
+		{"eth address is EIP-55 and re-parses", func() error {
+			ethAddr := keyinfo.EncodeEthAddr(pk)
+			ai, err := keyinfo.IdentifyAddress(ethAddr)
+			if err != nil {
+				return err
+			}
+			if ai.Type != keyinfo.AddressTypeEth || "0x"+hex.EncodeToString(ai.Hash) != strings.ToLower(ethAddr) {
+				return fmt.Errorf("%s does not re-parse as an eth address", ethAddr)
+			}
+			return nil
+		}},
+		{"short address and node ID round-trip", func() error {
+			short := keyinfo.EncodeShortAddr(pk)
+			nodeID, err := keyinfo.ShortAddressToNodeID(short)
+			if err != nil {
+				return err
+			}
+			back, err := keyinfo.NodeIDToShortAddress(nodeID)
+			if err != nil {
+				return err
+			}
+			if back != short {
+				return fmt.Errorf("%s round-tripped to %s", short, back)
+			}
+			return nil
+		}},
+		{"Avalanche message signature recovers the signer", func() error {
+			sig, err := keyinfo.SignMessage(pk, msg)
+			if err != nil {
+				return err
+			}
+			enc, err := keyinfo.EncodeSignature(sig)
+			if err != nil {
+				return err
+			}
+			if sig, err = keyinfo.DecodeSignature(enc); err != nil {
+				return err
+			}
+			signer, err := keyinfo.RecoverMessageSigner(msg, sig)
+			if err != nil {
+				return err
+			}
+			if signer != pk.PublicKey().Address() {
+				return errors.New("recovered a different signer")
+			}
+			return nil
+		}},
+		{"eth personal_sign signature recovers the signer", func() error {
+			sig, err := keyinfo.EthSignMessage(pk, msg)
+			if err != nil {
+				return err
+			}
+			signer, err := keyinfo.EthRecoverMessageSigner(msg, sig)
+			if err != nil {
+				return err
+			}
+			if signer != keyinfo.EncodeEthAddr(pk) {
+				return fmt.Errorf("recovered %s, expected %s", signer, keyinfo.EncodeEthAddr(pk))
+			}
+			return nil
+		}},
+		{"redacting writer (--redact-log) redacts the private keys", func() error {
+			enc, err := keyinfo.EncodePrivateKey(pk)
+			if err != nil {
+				return err
+			}
+			skHex := hex.EncodeToString(skBytes)
+			pubHex := hex.EncodeToString(pk.PublicKey().Bytes())
+			crafted := fmt.Sprintf("key %s\n{\"private_key_hex\": %q}\nexport PRIVATE_KEY_HEX='0x%s'\npublic key 0x%s, eth %s, redacted %s", enc, skHex, skHex, pubHex, keyinfo.EncodeEthAddr(pk), keyinfo.RedactPrivateKey(enc))
+			// byte by byte, so each secret is split across the writes
+			var out bytes.Buffer
+			rw := keyinfo.NewRedactingWriter(&out)
+			for i := 0; i < len(crafted); i++ {
+				if _, err := rw.Write([]byte{crafted[i]}); err != nil {
+					return err
+				}
+			}
+			if err := rw.Flush(); err != nil {
+				return err
+			}
+			if strings.Contains(out.String(), enc) || strings.Contains(out.String(), skHex) {
+				return errors.New("printed a private key")
+			}
+			for _, kept := range []string{pubHex, keyinfo.EncodeEthAddr(pk), keyinfo.RedactPrivateKey(enc), keyinfo.RedactSecret(skHex)} {
+				if !strings.Contains(out.String(), kept) {
+					return fmt.Errorf("redacted %q, which is not a private key", kept)
+				}
+			}
+			return nil
+		}},
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	failed := 0
+	for _, c := range checks {
+		result := "PASS"
+		if err := c.run(); err != nil {
+			result = fmt.Sprintf("FAIL (%v)", err)
+			failed++
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", c.name, result)
+	}
+	tw.Flush()
+
+	fmt.Printf("\n%s, %s, avalanchego %s\n", runtime.Version(), runtime.GOOS+"/"+runtime.GOARCH, avalanchegoVersion())
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	fmt.Println("SUCCESS")
+	return nil
+}
+
+// avalanchegoVersion returns the avalanchego module version of the build,
+// or "(unknown)" if the build info is not available.
+func avalanchegoVersion() string {
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range bi.Deps {
+			if dep.Path == "github.com/ava-labs/avalanchego" {
+				return dep.Version
+			}
+		}
+	}
+	return "(unknown)"
+}
+
+// go run main.go match-addresses /tmp/keys /tmp/addresses.txt
+// go run main.go match-addresses /tmp/keys /tmp/addresses.txt --format csv > /tmp/owners.csv
+// cat /tmp/addresses.txt | go run main.go match-addresses /tmp/keys -
+//
+// The addresses file has one address per line (X/P/C-chain, short, or eth),
+// and blank lines and lines starting with "#" are skipped. The addresses
+// are matched by their hash, so the chain alias and HRP do not matter.
+func matchAddresses(args []string) error {
+	fs := flag.NewFlagSet("match-addresses", flag.ContinueOnError)
+	format := fs.String("format", "text", "output format (text, csv)")
+	noHeader := noHeaderFlag(fs)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return usageError("expected 2 args: match-addresses [DIR-PATH] [ADDRESSES-PATH], got %q", args)
+	}
+	if *format != "text" && *format != "csv" {
+		return usageError("unknown --format %q (expected text or csv)", *format)
+	}
+
+	var b []byte
+	if args[1] == stdinPath {
+		b, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		b, err = ioutil.ReadFile(args[1])
+	}
+	if err != nil {
+		return ioError(err)
+	}
+	var addrs []string
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		addrs = append(addrs, line)
+	}
+	if len(addrs) == 0 {
+		return usageError("no addresses in %q", args[1])
+	}
+
+	idx := keyinfo.NewAddressIndex()
+	nkeys := 0
+	if err := filepath.Walk(args[0], func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return ioError(err)
+		}
+		if info.IsDir() || !strings.HasSuffix(info.Name(), keyFileSuffix) {
+			return nil
+		}
+		ki, err := loadKeyFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if err := idx.Add(path, ki); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		nkeys++
+		return nil
+	}); err != nil {
+		return err
+	}
+	logger.Infof("matching %d addresses against %d keys in %q", len(addrs), nkeys, args[0])
+
+	var cw *csv.Writer
+	var tw *tabwriter.Writer
+	if *format == "csv" {
+		cw = csv.NewWriter(os.Stdout)
+		if !*noHeader {
+			cw.Write([]string{"address", "key"})
+		}
+	} else {
+		tw = tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "ADDRESS\tKEY")
+	}
+	matched, unmatched := 0, 0
+	for _, addr := range addrs {
+		owner := "unknown"
+		names, err := idx.Lookup(addr)
+		switch {
+		case err != nil:
+			owner = fmt.Sprintf("invalid (%v)", err)
+			unmatched++
+		case len(names) == 0:
+			unmatched++
+		default:
+			owner = strings.Join(names, ",")
+			matched++
+		}
+		if cw != nil {
+			cw.Write([]string{addr, owner})
+		} else {
+			fmt.Fprintf(tw, "%s\t%s\n", addr, owner)
+		}
+	}
+	if cw != nil {
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return ioError(err)
+		}
+	} else {
+		tw.Flush()
+		fmt.Printf("\n%d matched, %d unmatched\n", matched, unmatched)
+	}
+	if unmatched > 0 {
+		return fmt.Errorf("%d of %d addresses do not belong to any key in %q", unmatched, len(addrs), args[0])
+	}
+	return nil
+}
+
+// go run main.go sign ../../artifacts/ewoq.key.json 9999 "hello world"
+// go run main.go sign ../../artifacts/ewoq.key.json 9999 --message-file /tmp/challenge.txt
+// go run main.go sign ../../artifacts/ewoq.key.json 9999 -- --offline
+func sign(args []string) error {
+	fs := flag.NewFlagSet("sign", flag.ContinueOnError)
+	messageFile := fs.String("message-file", "", "file to read the message from")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 2 {
+		return usageError("expected args: sign [KEY-PATH] [NETWORK-ID] [MESSAGE], got %q", args)
+	}
+	msg, err := readMessage(args[2:], *messageFile)
+	if err != nil {
+		return err
+	}
+	networkID, err := parseNetworkID(args[1])
+	if err != nil {
+		return err
+	}
+
+	ki, err := loadKeyFile(args[0])
+	if err != nil {
+		return err
+	}
+	pk, err := keyinfo.DecodePrivateKey(ki.PrivateKey)
+	if err != nil {
+		return err
+	}
+	sig, err := keyinfo.SignMessage(pk, msg)
+	if err != nil {
+		return err
+	}
+	encoded, err := keyinfo.EncodeSignature(sig)
+	if err != nil {
+		return err
+	}
+
+	signer, err := keyinfo.RecoverMessageSigner(msg, sig)
+	if err != nil {
+		return err
+	}
+	addr, err := keyinfo.FormatAddress("X", constants.GetHRP(networkID), signer.Bytes())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("signature: %s\n", encoded)
+	fmt.Printf("address: %s\n", addr)
+	return nil
+}
+
+// go run main.go verify-signature X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p "hello world" [SIGNATURE]
+// go run main.go verify-signature X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p [SIGNATURE] --message-file /tmp/challenge.txt
+func verifySignature(args []string) error {
+	fs := flag.NewFlagSet("verify-signature", flag.ContinueOnError)
+	messageFile := fs.String("message-file", "", "file to read the message from")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if (*messageFile == "" && len(args) != 3) || (*messageFile != "" && len(args) != 2) {
+		return usageError("expected args: verify-signature [ADDRESS] [MESSAGE] [SIGNATURE], got %q", args)
+	}
+	addr, sigStr := args[0], args[len(args)-1]
+	msg, err := readMessage(args[1:len(args)-1], *messageFile)
+	if err != nil {
+		return err
+	}
+
+	expected, err := keyinfo.ParseShortID(addr)
+	if err != nil {
+		return usageError("%v", err)
+	}
+	sig, err := keyinfo.DecodeSignature(sigStr)
+	if err != nil {
+		return usageError("invalid signature (%v)", err)
+	}
+	signer, err := keyinfo.RecoverMessageSigner(msg, sig)
+	if err != nil {
+		return err
+	}
+	if signer != expected {
+		return fmt.Errorf("signature was signed by %s, not by %q", signer, addr)
+	}
 
-// go run main.go ../../artifacts/ewoq.key.json 9999
-func main() {
-	if len(os.Args) != 3 {
-		panic(fmt.Errorf("expected 3 args, got %d", len(os.Args)))
+	fmt.Println("SUCCESS")
+	return nil
+}
+
+// go run main.go eth-sign ../../artifacts/ewoq.key.json "hello world"
+// go run main.go eth-sign ../../artifacts/ewoq.key.json --message-file /tmp/challenge.txt
+func ethSign(args []string) error {
+	fs := flag.NewFlagSet("eth-sign", flag.ContinueOnError)
+	messageFile := fs.String("message-file", "", "file to read the message from")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 1 {
+		return usageError("expected args: eth-sign [KEY-PATH] [MESSAGE], got %q", args)
+	}
+	msg, err := readMessage(args[1:], *messageFile)
+	if err != nil {
+		return err
 	}
 
-	networkID, err := strconv.ParseUint(os.Args[2], 10, 32)
+	ki, err := loadKeyFile(args[0])
+	if err != nil {
+		return err
+	}
+	pk, err := keyinfo.DecodePrivateKey(ki.PrivateKey)
 	if err != nil {
-		panic(err)
+		return err
 	}
+	sig, err := keyinfo.EthSignMessage(pk, msg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("signature: %s\n", keyinfo.EncodeEthSignature(sig))
+	fmt.Printf("address: %s\n", keyinfo.EncodeEthAddr(pk))
+	return nil
+}
 
-	b, err := ioutil.ReadFile(os.Args[1])
+// go run main.go eth-verify 0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC "hello world" [SIGNATURE]
+// go run main.go eth-verify ../../artifacts/ewoq.key.json "hello world" [SIGNATURE]
+func ethVerify(args []string) error {
+	fs := flag.NewFlagSet("eth-verify", flag.ContinueOnError)
+	messageFile := fs.String("message-file", "", "file to read the message from")
+	args, err := parseFlags(fs, args)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	if (*messageFile == "" && len(args) != 3) || (*messageFile != "" && len(args) != 2) {
+		return usageError("expected args: eth-verify [ETH-ADDRESS|KEY-PATH] [MESSAGE] [SIGNATURE], got %q", args)
+	}
+	sigStr := args[len(args)-1]
+	msg, err := readMessage(args[1:len(args)-1], *messageFile)
+	if err != nil {
+		return err
 	}
 
-	log.Print("loading key")
-	var ki1 keyInfo
-	if err := yaml.Unmarshal(b, &ki1); err != nil {
-		panic(err)
+	// either the eth address, or the key file to derive the eth address from
+	expected := args[0]
+	if !keyinfo.IsEthAddress(expected) {
+		ki, err := loadKeyFile(args[0])
+		if err != nil {
+			return err
+		}
+		pk, err := keyinfo.DecodePrivateKey(ki.PrivateKey)
+		if err != nil {
+			return err
+		}
+		expected = keyinfo.EncodeEthAddr(pk)
 	}
-	fmt.Println(string(b))
 
-	pk, err := decodePrivateKey(ki1.PrivateKey)
+	sig, err := keyinfo.DecodeEthSignature(sigStr)
+	if err != nil {
+		return usageError("invalid signature (%v)", err)
+	}
+	signer, err := keyinfo.EthRecoverMessageSigner(msg, sig)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(signer, expected) {
+		return fmt.Errorf("signature was signed by %s, not by %s", signer, expected)
+	}
+
+	fmt.Println("SUCCESS")
+	return nil
+}
+
+// go run main.go export-keystore ../../artifacts/ewoq.key.json /tmp/ewoq.keystore.json --passphrase-file /tmp/passphrase
+// KEYSTORE_PASSPHRASE=... go run main.go export-keystore ../../artifacts/ewoq.key.json /tmp/ewoq.keystore.json
+func exportKeystore(args []string) error {
+	fs := flag.NewFlagSet("export-keystore", flag.ContinueOnError)
+	passphraseFile := passphraseFileFlag(fs)
+	force := fs.Bool("force", false, "overwrite the output file if it already exists")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return usageError("expected 2 args: export-keystore [KEY-PATH] [OUTPUT-PATH], got %q", args)
+	}
+	fpath := args[1]
+	if _, err := os.Stat(fpath); err == nil && !*force {
+		return usageError("%q already exists (use --force to overwrite)", fpath)
+	}
+	passphrase, err := readPassphrase(*passphraseFile)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	pkEncoded, err := encodePrivateKey(pk)
+	ki, err := loadKeyFile(args[0])
+	if err != nil {
+		return err
+	}
+	pk, err := keyinfo.DecodePrivateKey(ki.PrivateKey)
+	if err != nil {
+		return err
+	}
+	logger.Infof("encrypting key")
+	b, err := keyinfo.EncryptKeystore(pk, passphrase)
 	if err != nil {
-		panic(err)
+		return err
+	}
+
+	logger.Infof("saving to %q", fpath)
+	if err := ioutil.WriteFile(fpath, b, fsModeWrite); err != nil {
+		return ioError(err)
 	}
-	pkDecoded, err := decodePrivateKey(pkEncoded)
+	fmt.Println(ki.EthAddress)
+	return nil
+}
+
+// go run main.go export-eth-key ../../artifacts/ewoq.key.json --i-understand-this-exposes-my-key
+// go run main.go export-eth-key ../../artifacts/ewoq.key.json --i-understand-this-exposes-my-key --out /tmp/ewoq.eth.key
+//
+// Prints the "0x"-prefixed 32-byte hex private key, which is what MetaMask
+// "Import account" expects for the C-chain eth address.
+func exportEthKey(args []string) error {
+	fs := flag.NewFlagSet("export-eth-key", flag.ContinueOnError)
+	confirmed := fs.Bool("i-understand-this-exposes-my-key", false, "confirm printing the private key in plaintext")
+	out := fs.String("out", "", "file to write the private key to instead of stdout")
+	force := fs.Bool("force", false, "overwrite the --out file if it already exists")
+	args, err := parseFlags(fs, args)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	if len(args) != 1 {
+		return usageError("expected 1 arg: export-eth-key [KEY-PATH], got %q", args)
 	}
-	if !bytes.Equal(pk.Bytes(), pkDecoded.Bytes()) {
-		panic(fmt.Errorf("pk.Bytes %s != pkDecoded.Bytes %s", pk.Bytes(), pkDecoded.Bytes()))
+	if !*confirmed {
+		return usageError("export-eth-key prints the private key in plaintext, anyone who sees it controls the funds (pass --i-understand-this-exposes-my-key to continue)")
+	}
+	if *out != "" && !*force {
+		if _, err := os.Stat(*out); err == nil {
+			return usageError("%q already exists (use --force to overwrite)", *out)
+		}
 	}
 
-	xMainAddr, err := encodeAddr(pk, "X", constants.GetHRP(uint32(networkID)))
+	ki, err := loadKeyFile(args[0])
+	if err != nil {
+		return err
+	}
+	pk, err := keyinfo.DecodePrivateKey(ki.PrivateKey)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	// the account MetaMask imports must be the one in the key file
+	ethAddr := keyinfo.EncodeEthAddr(pk)
+	if ki.EthAddress != "" && !strings.EqualFold(ki.EthAddress, ethAddr) {
+		return fmt.Errorf("eth_address %s in the key file is not the private key's eth address %s", ki.EthAddress, ethAddr)
+	}
+	logger.Infof("MetaMask imports this key as %s", ethAddr)
+	ethKey := "0x" + hex.EncodeToString(pk.Bytes())
+
+	if *out == "" {
+		fmt.Println(ethKey)
+		return nil
 	}
-	pMainAddr, err := encodeAddr(pk, "P", constants.GetHRP(uint32(networkID)))
+	logger.Infof("saving to %q", *out)
+	if err := ioutil.WriteFile(*out, []byte(ethKey+"\n"), fsModeWrite); err != nil {
+		return ioError(err)
+	}
+	return nil
+}
+
+// go run main.go import-keystore /tmp/ewoq.keystore.json 9999 --passphrase-file /tmp/passphrase
+// go run main.go import-keystore /tmp/ewoq.keystore.json 9999 --passphrase-file /tmp/passphrase --key-file ../../artifacts/ewoq.key.json
+func importKeystore(args []string) error {
+	fs := flag.NewFlagSet("import-keystore", flag.ContinueOnError)
+	passphraseFile := passphraseFileFlag(fs)
+	keyFile := fs.String("key-file", "", "original key file to compare the decrypted addresses against")
+	args, err := parseFlags(fs, args)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	if len(args) != 2 {
+		return usageError("expected 2 args: import-keystore [KEYSTORE-PATH] [NETWORK-ID], got %q", args)
 	}
-	cMainAddr, err := encodeAddr(pk, "C", constants.GetHRP(uint32(networkID)))
+	networkID, err := parseNetworkID(args[1])
 	if err != nil {
-		panic(err)
+		return err
 	}
-	shortAddr := encodeShortAddr1(pk)
-	if addr2 := encodeShortAddr2(pk); shortAddr != addr2 {
-		panic(fmt.Errorf("short address %s != %s", shortAddr, addr2))
+	passphrase, err := readPassphrase(*passphraseFile)
+	if err != nil {
+		return err
 	}
 
-	ki2 := keyInfo{
-		PrivateKey:    pkEncoded,
-		PrivateKeyHex: hex.EncodeToString(pk.Bytes()),
-		XAddress:      xMainAddr,
-		PAddress:      pMainAddr,
-		CAddress:      cMainAddr,
-		ShortAddress:  shortAddr,
-		EthAddress:    encodeEthAddr(pk),
+	b, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return ioError(err)
+	}
+	logger.Infof("decrypting keystore")
+	pk, err := keyinfo.DecryptKeystore(b, passphrase)
+	if err != nil {
+		return err
 	}
-	if !reflect.DeepEqual(ki1, ki2) {
-		panic(fmt.Errorf("go key info %+v != loaded key info %+v", ki2, ki1))
+	ki, err := keyinfo.NewInfoFromPrivateKey(pk, networkID)
+	if err != nil {
+		return err
+	}
+
+	if err := compareKeyFile(ki, *keyFile, networkID, "decrypted"); err != nil {
+		return err
 	}
 
+	fmt.Println(ki.XAddress)
+	fmt.Println(ki.PAddress)
+	fmt.Println(ki.CAddress)
+	fmt.Println(ki.EthAddress)
 	fmt.Println("SUCCESS")
+	return nil
 }
 
-type keyInfo struct {
-	PrivateKey string `json:"private_key"`
-	// ref. https://github.com/ava-labs/subnet-cli/blob/5b69345a3fba534fb6969002f41c8d3e69026fed/internal/key/key.go#L238-L258
-	PrivateKeyHex string `json:"private_key_hex"`
-	XAddress      string `json:"x_address"`
-	PAddress      string `json:"p_address"`
-	CAddress      string `json:"c_address"`
-	ShortAddress  string `json:"short_address"`
-	EthAddress    string `json:"eth_address"`
+// compareKeyFile checks that the addresses of the imported key are the ones
+// of the original key file, if any (e.g., "--key-file" of import-keystore).
+func compareKeyFile(ki keyinfo.Info, keyFile string, networkID uint32, imported string) error {
+	if keyFile == "" {
+		return nil
+	}
+	orig, err := loadKeyFile(keyFile)
+	if err != nil {
+		return err
+	}
+	if err := keyinfo.Validate(orig, networkID); err != nil {
+		return fmt.Errorf("invalid --key-file %q (%w)", keyFile, err)
+	}
+	for _, f := range []struct{ name, imported, original string }{
+		{"x_address", ki.XAddress, orig.XAddress},
+		{"p_address", ki.PAddress, orig.PAddress},
+		{"c_address", ki.CAddress, orig.CAddress},
+		{"short_address", ki.ShortAddress, orig.ShortAddress},
+		{"eth_address", ki.EthAddress, orig.EthAddress},
+	} {
+		if f.imported != f.original {
+			return fmt.Errorf("%s %s %q != original %q", imported, f.name, f.imported, f.original)
+		}
+	}
+	return nil
 }
 
-const privKeyEncPfx = "PrivateKey-"
+// go run main.go export-pem ../../artifacts/ewoq.key.json /tmp/ewoq.pem
+// go run main.go export-pem ../../artifacts/ewoq.key.json /tmp/ewoq.pkcs8.pem --pkcs8
+//
+// Writes the unencrypted SEC1 (or PKCS#8) PEM of the private key,
+// which OpenSSL reads as is (e.g., "openssl ec -in /tmp/ewoq.pem -text").
+func exportPEM(args []string) error {
+	fs := flag.NewFlagSet("export-pem", flag.ContinueOnError)
+	pkcs8 := fs.Bool("pkcs8", false, "write the PKCS#8 \"PRIVATE KEY\" PEM instead of the SEC1 \"EC PRIVATE KEY\" PEM")
+	force := fs.Bool("force", false, "overwrite the output file if it already exists")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return usageError("expected 2 args: export-pem [KEY-PATH] [OUTPUT-PATH], got %q", args)
+	}
+	fpath := args[1]
+	if _, err := os.Stat(fpath); err == nil && !*force {
+		return usageError("%q already exists (use --force to overwrite)", fpath)
+	}
 
-func encodePrivateKey(pk *crypto.PrivateKeySECP256K1R) (string, error) {
-	privKeyRaw := pk.Bytes()
-	enc, err := formatting.EncodeWithChecksum(formatting.CB58, privKeyRaw)
+	ki, err := loadKeyFile(args[0])
+	if err != nil {
+		return err
+	}
+	pk, err := keyinfo.DecodePrivateKey(ki.PrivateKey)
+	if err != nil {
+		return err
+	}
+	b, err := keyinfo.EncodePEM(pk, *pkcs8)
 	if err != nil {
-		return "", err
+		return err
+	}
+
+	logger.Infof("saving to %q", fpath)
+	if err := ioutil.WriteFile(fpath, b, fsModeWrite); err != nil {
+		return ioError(err)
 	}
-	return privKeyEncPfx + enc, nil
+	fmt.Println(keyinfo.EncodeEthAddr(pk))
+	return nil
 }
 
-func decodePrivateKey(enc string) (*crypto.PrivateKeySECP256K1R, error) {
-	rawPk := strings.Replace(enc, privKeyEncPfx, "", 1)
-	skBytes, err := formatting.Decode(formatting.CB58, rawPk)
+// go run main.go import-pem /tmp/ewoq.pem 9999
+// go run main.go import-pem /tmp/ewoq.pem 9999 --key-file ../../artifacts/ewoq.key.json
+// openssl ecparam -name secp256k1 -genkey -out /tmp/openssl.pem && go run main.go import-pem /tmp/openssl.pem 9999
+func importPEM(args []string) error {
+	fs := flag.NewFlagSet("import-pem", flag.ContinueOnError)
+	keyFile := fs.String("key-file", "", "original key file to compare the imported addresses against")
+	args, err := parseFlags(fs, args)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	rpk, err := keyFactory.ToPrivateKey(skBytes)
+	if len(args) != 2 {
+		return usageError("expected 2 args: import-pem [PEM-PATH] [NETWORK-ID], got %q", args)
+	}
+	networkID, err := parseNetworkID(args[1])
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	b, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return ioError(err)
+	}
+	pk, err := keyinfo.DecodePEM(b)
+	if err != nil {
+		return err
+	}
+	ki, err := keyinfo.NewInfoFromPrivateKey(pk, networkID)
+	if err != nil {
+		return err
+	}
+	if err := compareKeyFile(ki, *keyFile, networkID, "imported"); err != nil {
+		return err
+	}
+
+	fmt.Println(ki.XAddress)
+	fmt.Println(ki.PAddress)
+	fmt.Println(ki.CAddress)
+	fmt.Println(ki.EthAddress)
+	fmt.Println("SUCCESS")
+	return nil
+}
+
+// go run main.go encrypt-file ../../artifacts/ewoq.key.json /tmp/ewoq.encrypted.json --passphrase-file /tmp/passphrase
+// go run main.go encrypt-file /tmp/test.key.json /tmp/test.key.json --passphrase-file /tmp/passphrase --force
+//
+// Encrypts the key file with the passphrase (AES-256-GCM, scrypt), which
+// every mode decrypts on read with "--key-passphrase-file" (or $KEYSTORE_PASSPHRASE).
+func encryptFile(args []string) error {
+	fs := flag.NewFlagSet("encrypt-file", flag.ContinueOnError)
+	passphraseFile := passphraseFileFlag(fs)
+	force := fs.Bool("force", false, "overwrite the output file if it already exists (e.g., encrypt the key file in place)")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return usageError("expected 2 args: encrypt-file [KEY-PATH] [OUTPUT-PATH], got %q", args)
+	}
+	fpath := args[1]
+	if _, err := os.Stat(fpath); err == nil && !*force {
+		return usageError("%q already exists (use --force to overwrite)", fpath)
+	}
+	passphrase, err := readPassphrase(*passphraseFile)
+	if err != nil {
+		return err
+	}
+
+	b, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return ioError(err)
+	}
+	if keyinfo.IsEncryptedFile(b) {
+		return usageError("%q is already encrypted", args[0])
+	}
+	// only the valid key info files, so that the decrypted file is usable
+	var ki keyinfo.Info
+	if err := unmarshalKeyInfo(b, &ki); err != nil {
+		return err
+	}
+	if ki.PrivateKey == "" {
+		return usageError("%q has no private_key to encrypt", args[0])
+	}
+	logger.Infof("encrypting key file")
+	eb, err := keyinfo.EncryptFile(b, passphrase)
+	if err != nil {
+		return err
+	}
+	for i := range b {
+		b[i] = 0
+	}
+
+	logger.Infof("saving to %q", fpath)
+	if err := ioutil.WriteFile(fpath, eb, fsModeWrite); err != nil {
+		return ioError(err)
+	}
+	fmt.Println(ki.XAddress)
+	return nil
+}
+
+// passphraseFileFlag registers the "--passphrase-file" flag.
+func passphraseFileFlag(fs *flag.FlagSet) *string {
+	return fs.String("passphrase-file", "", "file to read the passphrase from (default $"+passphraseEnv+")")
+}
+
+const passphraseEnv = "KEYSTORE_PASSPHRASE"
+
+// readPassphrase reads the keystore passphrase from the passphrase file,
+// or from the environment variable if not set.
+// The passphrase must never be logged.
+func readPassphrase(passphraseFile string) (string, error) {
+	var passphrase string
+	if passphraseFile != "" {
+		b, err := ioutil.ReadFile(passphraseFile)
+		if err != nil {
+			return "", ioError(err)
+		}
+		passphrase = strings.TrimRight(string(b), "\r\n")
+		for i := range b {
+			b[i] = 0
+		}
+	} else {
+		passphrase = os.Getenv(passphraseEnv)
+	}
+	if passphrase == "" {
+		return "", usageError("empty passphrase (set --passphrase-file or $%s)", passphraseEnv)
+	}
+	return passphrase, nil
+}
+
+// readMessage returns the message from the positional argument,
+// or from the message file if set.
+func readMessage(args []string, messageFile string) ([]byte, error) {
+	if messageFile != "" {
+		if len(args) != 0 {
+			return nil, usageError("unexpected message argument with --message-file")
+		}
+		b, err := ioutil.ReadFile(messageFile)
+		if err != nil {
+			return nil, ioError(err)
+		}
+		return b, nil
+	}
+	if len(args) != 1 {
+		return nil, usageError("expected 1 message argument or --message-file, got %q", args)
+	}
+	return []byte(args[0]), nil
+}
+
+// loadKeyFile loads the key info from the YAML or JSON key file.
+func loadKeyFile(fpath string) (keyinfo.Info, error) {
+	b, err := readKeyFile(fpath)
+	if err != nil {
+		return keyinfo.Info{}, err
+	}
+	return decodeKey(b, keyFormatKeyInfo, 0, "")
+}
+
+// resolveFileNetworkID returns the network ID of the --network name or
+// the [NETWORK-ID] argument, or the "network_id" of the key file if both are
+// omitted. The key file "network_id" must match the network ID, if both are set.
+func resolveFileNetworkID(args []string, networkName string, keyFormat string) (uint32, error) {
+	var fileNetworkID uint32
+	if keyFormat == keyFormatKeyInfo {
+		ki, err := loadKeyFile(args[0])
+		if err != nil {
+			return 0, err
+		}
+		fileNetworkID = ki.NetworkID
+	}
+	arg := optionalArg(args, 1)
+	if arg == "" && networkName == "" {
+		if fileNetworkID == 0 {
+			return 0, usageError("no [NETWORK-ID] or --network given, and the key file %q has no network_id", args[0])
+		}
+		logger.Infof("using network_id %d from the key file", fileNetworkID)
+		return fileNetworkID, nil
+	}
+	networkID, err := resolveNetwork(networkName, arg)
+	if err != nil {
+		return 0, err
+	}
+	if fileNetworkID != 0 && fileNetworkID != networkID {
+		return 0, fmt.Errorf("network ID %d disagrees with network_id %d in the key file %q", networkID, fileNetworkID, args[0])
+	}
+	return networkID, nil
+}
+
+// stdinPath is the key path to read the key from stdin.
+const stdinPath = "-"
+
+// defaultEndpoint is the "--endpoint" of the local avalanchego node.
+const defaultEndpoint = "http://127.0.0.1:9650"
+
+// balanceTimeout is the timeout to fetch all the balances of "--check-balance".
+const balanceTimeout = 30 * time.Second
+
+// chainBalance is the balance of the key on a chain, or the error of its lookup.
+type chainBalance struct {
+	chain   string
+	address string
+	// balance is in nAVAX on the X and P-chain, and in wei on the C-chain.
+	balance string
+	unit    string
+	err     error
+}
+
+// balanceFetcher fetches the X, P, and C-chain balances of the key from the
+// avalanchego API endpoint, for the network ID. It registers itself behind
+// the "balance" build tag (see "balance_api.go"), so the default build never
+// accesses the network.
+var balanceFetcher func(ctx context.Context, endpoint string, ki keyinfo.Info, networkID uint32) ([]chainBalance, error)
+
+// printBalances prints the balances of the key from the endpoint. The lookup
+// is only informational: a timeout, an unreachable endpoint, or an endpoint
+// of another network is logged as a warning, and never fails the validation.
+func printBalances(endpoint string, ki keyinfo.Info, networkID uint32) {
+	logger.Infof("fetching the balances from %q", endpoint)
+	ctx, cancel := context.WithTimeout(context.Background(), balanceTimeout)
+	defer cancel()
+	balances, err := balanceFetcher(ctx, endpoint, ki, networkID)
+	if err != nil {
+		logger.Warnf("--check-balance: failed to fetch the balances from %q (%v)", endpoint, err)
+		fmt.Printf("balance: unknown (%v)\n", err)
+		return
+	}
+	for _, b := range balances {
+		if b.err != nil {
+			logger.Warnf("--check-balance: failed to fetch the %s-chain balance of %s (%v)", b.chain, b.address, b.err)
+			fmt.Printf("%s-chain balance of %s: unknown\n", b.chain, b.address)
+			continue
+		}
+		fmt.Printf("%s-chain balance of %s: %s %s\n", b.chain, b.address, b.balance, b.unit)
+	}
+}
+
+// stdinBytes caches stdin, so the key can be read more than once.
+var stdinBytes []byte
+
+// keySourceTimeout is the timeout to fetch the key from "--key-source".
+const keySourceTimeout = 30 * time.Second
+
+// keySourceFetchers maps the "--key-source" URI scheme to the function
+// that fetches the key file contents from the location after "://".
+// The secret store fetchers register themselves behind their build tags
+// (e.g., "key_source_aws.go"), so the default build has no cloud SDK dependency.
+var keySourceFetchers = map[string]func(ctx context.Context, location string) ([]byte, error){
+	"env": fetchEnvVar,
+}
+
+// keySourceTags maps the known "--key-source" URI schemes to the build tag
+// that registers their fetchers.
+var keySourceTags = map[string]string{
+	"ssm":            "aws",
+	"secretsmanager": "aws",
+}
+
+// fetchKeySource fetches the key file contents from the "--key-source" URI,
+// [SCHEME]://[LOCATION], or env:[VAR] for the environment variables.
+func fetchKeySource(uri string) ([]byte, error) {
+	idx, sep := strings.Index(uri, "://"), 3
+	if strings.HasPrefix(uri, "env:") && idx < 0 {
+		idx, sep = len("env"), 1
+	}
+	if idx <= 0 || idx+sep == len(uri) {
+		return nil, usageError("invalid --key-source %q (expected [SCHEME]://[LOCATION] or env:[VAR])", uri)
+	}
+	scheme, location := uri[:idx], uri[idx+sep:]
+	if scheme != "env" {
+		if err := checkOnline("--key-source " + scheme + "://"); err != nil {
+			return nil, err
+		}
+	}
+	fetch, ok := keySourceFetchers[scheme]
+	if !ok {
+		if tag, known := keySourceTags[scheme]; known {
+			return nil, usageError("--key-source %s:// is not built in, rebuild with \"-tags %s\"", scheme, tag)
+		}
+		return nil, usageError("unknown --key-source scheme %q (expected env, ssm, or secretsmanager)", scheme)
+	}
+	logger.Debugf("fetching the key from --key-source %s", uri)
+	ctx, cancel := context.WithTimeout(context.Background(), keySourceTimeout)
+	defer cancel()
+	b, err := fetch(ctx, location)
+	if err != nil {
+		return nil, ioError(fmt.Errorf("failed to fetch --key-source %s (%w)", uri, err))
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil, fmt.Errorf("--key-source %s is empty", uri)
 	}
-	privKey, ok := rpk.(*crypto.PrivateKeySECP256K1R)
+	return b, nil
+}
+
+// fetchEnvVar returns the value of the environment variable (e.g., a private
+// key injected by the container orchestrator), which is never logged.
+func fetchEnvVar(_ context.Context, name string) ([]byte, error) {
+	if !envNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid environment variable name %q", name)
+	}
+	v, ok := os.LookupEnv(name)
 	if !ok {
-		return nil, fmt.Errorf("invalid type %T", rpk)
+		return nil, fmt.Errorf("environment variable %s is not set", name)
+	}
+	if strings.TrimSpace(v) == "" {
+		return nil, fmt.Errorf("environment variable %s is empty", name)
+	}
+	return []byte(v), nil
+}
+
+// readKeyFile reads the key file, or stdin if the path is "-".
+func readKeyFile(fpath string) ([]byte, error) {
+	logger.Debugf("reading %q", fpath)
+	if fpath != stdinPath {
+		b, err := ioutil.ReadFile(fpath)
+		if err != nil {
+			return nil, ioError(err)
+		}
+		return decryptKeyFile(fpath, b)
+	}
+	if stdinBytes == nil {
+		b, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, ioError(err)
+		}
+		stdinBytes = b
+	}
+	if len(bytes.TrimSpace(stdinBytes)) == 0 {
+		return nil, usageError("no input provided on stdin")
+	}
+	return decryptKeyFile(fpath, stdinBytes)
+}
+
+var (
+	// keyPassphraseFile is the "--key-passphrase-file" for the encrypted input key files,
+	// which falls back to the passphrase environment variable.
+	keyPassphraseFile string
+
+	keyPassphraseOnce sync.Once
+	keyPassphrase     string
+	keyPassphraseErr  error
+
+	decryptedMu sync.Mutex
+	// decrypted caches the decrypted contents by the encrypted file contents,
+	// since each decryption runs scrypt. The plaintext is only kept in memory.
+	decrypted = make(map[string][]byte)
+	// encryptedPaths is the input key files that were encrypted,
+	// which must never be rewritten in plaintext.
+	encryptedPaths = make(map[string]bool)
+)
+
+// decryptKeyFile returns the decrypted key file contents if the file is
+// the passphrase-encrypted envelope (see encrypt-file), or the contents as is.
+func decryptKeyFile(fpath string, b []byte) ([]byte, error) {
+	if !keyinfo.IsEncryptedFile(b) {
+		return b, nil
+	}
+	keyPassphraseOnce.Do(func() {
+		if keyPassphraseFile == "" && os.Getenv(passphraseEnv) == "" {
+			keyPassphraseErr = usageError("no passphrase for the encrypted key file (set --key-passphrase-file or $%s)", passphraseEnv)
+			return
+		}
+		keyPassphrase, keyPassphraseErr = readPassphrase(keyPassphraseFile)
+	})
+	if keyPassphraseErr != nil {
+		return nil, fmt.Errorf("%q: %w", fpath, keyPassphraseErr)
+	}
+
+	decryptedMu.Lock()
+	defer decryptedMu.Unlock()
+	encryptedPaths[fpath] = true
+	if pt, ok := decrypted[string(b)]; ok {
+		return pt, nil
+	}
+	logger.Infof("decrypting %q", fpath)
+	pt, err := keyinfo.DecryptFile(b, keyPassphrase)
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", fpath, err)
+	}
+	decrypted[string(b)] = pt
+	return pt, nil
+}
+
+// wasEncrypted returns true if the key file at fpath was read decrypted.
+func wasEncrypted(fpath string) bool {
+	decryptedMu.Lock()
+	defer decryptedMu.Unlock()
+	return encryptedPaths[fpath]
+}
+
+// sealKeyFile re-encrypts the contents to write for the key file at fpath,
+// with the same passphrase it was decrypted with, if it was encrypted.
+func sealKeyFile(fpath string, b []byte) ([]byte, error) {
+	if !wasEncrypted(fpath) {
+		return b, nil
+	}
+	logger.Infof("re-encrypting the key file from the encrypted %q", fpath)
+	return keyinfo.EncryptFile(b, keyPassphrase)
+}
+
+const (
+	// keyFormatKeyInfo is the YAML or JSON key info file (e.g., "artifacts/ewoq.key.json").
+	keyFormatKeyInfo = "keyinfo"
+	// keyFormatHex is the file with the 32-byte hex-encoded private key.
+	keyFormatHex = "hex"
+	// keyFormatSubnetCLI is the subnet-cli private key file, which is the
+	// hex-encoded private key without "0x" (e.g., "subnet-cli create key"),
+	// or the "PrivateKey-" (or --key-prefix) prefixed CB58 private key. It maps to
+	// "private_key_hex" (or "private_key"), and the rest is derived.
+	// ref. https://github.com/ava-labs/subnet-cli/blob/5b69345a3fba534fb6969002f41c8d3e69026fed/internal/key/key.go#L238-L258
+	keyFormatSubnetCLI = "subnet-cli"
+	// keyFormatBase64 is the file with the 32-byte base64-encoded private key
+	// in the standard or the URL-safe alphabet (e.g., AWS Secrets Manager blobs).
+	keyFormatBase64 = "base64"
+)
+
+// decodeKey decodes the key file contents in the key format.
+// The key info is derived for the network ID and HRP if the format only has the private key.
+func decodeKey(b []byte, keyFormat string, networkID uint32, hrp string) (keyinfo.Info, error) {
+	switch keyFormat {
+	case keyFormatKeyInfo:
+		if !skipSchema {
+			if err := validateKeySchema(b); err != nil {
+				return keyinfo.Info{}, err
+			}
+		}
+		var ki keyinfo.Info
+		if err := unmarshalKeyInfo(b, &ki); err != nil {
+			return keyinfo.Info{}, err
+		}
+		return ki, nil
+	case keyFormatHex, keyFormatSubnetCLI, keyFormatBase64:
+		enc := strings.TrimSpace(string(b))
+		decode := keyinfo.DecodePrivateKeyFromHex
+		switch {
+		case keyFormat == keyFormatBase64:
+			decode = keyinfo.DecodePrivateKeyFromBase64
+		case keyFormat == keyFormatSubnetCLI && strings.ContainsRune(enc, '-'):
+			decode = keyinfo.DecodePrivateKey
+		}
+		pk, err := decode(enc)
+		if err != nil {
+			return keyinfo.Info{}, err
+		}
+		return keyinfo.NewInfoFromPrivateKeyWithHRP(pk, networkID, hrp)
 	}
-	return privKey, nil
+	return keyinfo.Info{}, usageError("unknown key format %q", keyFormat)
 }
 
-func encodeShortAddr1(pk *crypto.PrivateKeySECP256K1R) string {
-	pubBytes := pk.PublicKey().Address().Bytes()
-	str, _ := formatting.EncodeWithChecksum(formatting.CB58, pubBytes)
-	return str
+// skipSchema is the "--skip-schema" flag, which applies to all modes,
+// to decode the key info files as is, without the schema validation.
+var skipSchema bool
+
+// validateKeySchema validates the JSON or YAML key info file against the
+// embedded schema (see keyinfo.Schema), reporting each field of a wrong type
+// or missing. The files that do not parse are left to unmarshalKeyInfo,
+// which reports the line and column of the syntax errors.
+func validateKeySchema(b []byte) error {
+	jb := b
+	if !bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		var err error
+		if jb, err = yaml.YAMLToJSON(b); err != nil {
+			return nil
+		}
+	}
+	if !json.Valid(jb) {
+		return nil
+	}
+	if err := keyinfo.ValidateSchema(jb); err != nil {
+		return fmt.Errorf("%v (use --skip-schema to decode it anyway)", err)
+	}
+	return nil
+}
+
+// unmarshalKeyInfo decodes the key info file as JSON if it starts with "{",
+// and as YAML otherwise, so the parse errors are specific to the format
+// (YAML also accepts JSON, but reports a corrupted JSON file as a YAML error).
+func unmarshalKeyInfo(b []byte, ki *keyinfo.Info) error {
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		err := json.Unmarshal(b, ki)
+		var offset int64 = -1
+		var serr *json.SyntaxError
+		var terr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &serr):
+			offset = serr.Offset
+		case errors.As(err, &terr):
+			offset = terr.Offset
+		}
+		if offset >= 0 {
+			line, col := lineColumn(b, offset)
+			return fmt.Errorf("invalid JSON key file at line %d, column %d (%v)", line, col, err)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid JSON key file (%v)", err)
+		}
+		return nil
+	}
+
+	err := yaml.Unmarshal(b, ki)
+	if err == nil {
+		return nil
+	}
+	msg := strings.TrimPrefix(err.Error(), "error converting YAML to JSON: ")
+	if (bytes.Contains(b, []byte("\n\t")) || bytes.HasPrefix(b, []byte("\t"))) && !strings.Contains(msg, "tab") {
+		msg += ", YAML does not allow tabs for indentation"
+	}
+	return fmt.Errorf("invalid YAML key file (%s)", msg)
+}
+
+// lineColumn returns the 1-based line and column of the byte offset,
+// which is the byte after the error in the JSON errors.
+func lineColumn(b []byte, offset int64) (int, int) {
+	if offset > int64(len(b)) {
+		offset = int64(len(b))
+	}
+	before := b[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// validateFile loads the key file and validates it against the network ID and HRP.
+// With strict, every stored field must exactly match the derived value.
+func validateFile(fpath string, keyFormat string, networkID uint32, hrp string, strict bool) (keyinfo.Info, error) {
+	b, err := readKeyFile(fpath)
+	if err != nil {
+		return keyinfo.Info{}, err
+	}
+	ki, err := decodeKey(b, keyFormat, networkID, hrp)
+	if err != nil {
+		return keyinfo.Info{}, err
+	}
+	return ki, validateKey(ki, networkID, hrp, strict)
+}
+
+// validateKey validates the key info with keyinfo.ValidateFields if --fields
+// is set, keyinfo.ValidateStrict if strict, or with keyinfo.ValidateWithHRP
+// otherwise. It warns if the network ID
+// falls back to the "custom" HRP but the key was made for a standard network,
+// and if a mainnet key is used for another network or vice versa (an error
+// if strict). If the key fails the validation but is valid for the network
+// of its stored addresses, it returns the exitCodeWrongNetwork error.
+func validateKey(ki keyinfo.Info, networkID uint32, hrp string, strict bool) error {
+	if err := validateKeyFor(ki, networkID, hrp, strict); err != nil {
+		return wrongNetworkError(ki, networkID, hrp, err)
+	}
+	return nil
+}
+
+func validateKeyFor(ki keyinfo.Info, networkID uint32, hrp string, strict bool) error {
+	if _, fileHRP, _, err := formatting.ParseAddress(ki.XAddress); err == nil {
+		if err := keyinfo.CheckFallbackHRP(networkID, hrp, fileHRP); err != nil {
+			logger.Warnf("%v", err)
+		}
+		if err := keyinfo.CheckMainnetReuse(networkID, hrp, fileHRP); err != nil {
+			if strict {
+				// a key for another network, before any other validation
+				return &exitError{code: exitCodeWrongNetwork, err: err}
+			}
+			logger.Warnf("%v", err)
+		}
+	}
+	if onlyFields != nil {
+		return keyinfo.ValidateFields(ki, networkID, hrp, onlyFields)
+	}
+	if strict {
+		return keyinfo.ValidateStrict(ki, networkID, hrp)
+	}
+	return keyinfo.ValidateWithHRP(ki, networkID, hrp)
+}
+
+// wrongNetworkError returns the exitCodeWrongNetwork error if the key info
+// is internally consistent for the network of its stored addresses (the
+// stored "network_id", or of the X-chain address HRP), but not for the
+// requested network, and the validation error otherwise.
+func wrongNetworkError(ki keyinfo.Info, networkID uint32, hrp string, err error) error {
+	var ee *exitError
+	if errors.As(err, &ee) {
+		return err
+	}
+	_, fileHRP, _, perr := formatting.ParseAddress(ki.XAddress)
+	if perr != nil {
+		return err
+	}
+	fileNetworkID, known := ki.NetworkID, ki.NetworkID != 0
+	if !known {
+		fileNetworkID, known = constants.NetworkHRPToNetworkID[fileHRP]
+	}
+	if !known {
+		// e.g., the "custom" HRP of any non-standard network ID
+		fileNetworkID = networkID
+	}
+	if fileNetworkID == networkID && fileHRP == hrp {
+		return err
+	}
+	if keyinfo.ValidateWithHRP(ki, fileNetworkID, fileHRP) != nil {
+		return err
+	}
+	logger.Debugf("validation failed for the requested network (%v)", err)
+	if !known {
+		return &exitError{code: exitCodeWrongNetwork, err: fmt.Errorf("the key file is valid, but for a custom network with HRP %q, not for %s with HRP %q (use a custom network ID or --hrp %s, or recompute the addresses with --out)",
+			fileHRP, keyinfo.NetworkLabel(networkID), hrp, fileHRP)}
+	}
+	return &exitError{code: exitCodeWrongNetwork, err: fmt.Errorf("the key file is valid, but for %s with HRP %q, not for %s with HRP %q (use network ID %d, or recompute the addresses with --out)",
+		keyinfo.NetworkLabel(fileNetworkID), fileHRP, keyinfo.NetworkLabel(networkID), hrp, fileNetworkID)}
+}
+
+func fieldsFlag(fs *flag.FlagSet) *string {
+	return fs.String("fields", "", "comma-separated fields to compare strictly, ignoring the other stored fields ("+keyinfo.FieldNames()+")")
+}
+
+// onlyFields are the key file fields of the "--fields" flag,
+// or nil to compare every field.
+var onlyFields []string
+
+// parseFields parses the "--fields" flag into onlyFields.
+func parseFields(fields string) error {
+	if fields == "" {
+		return nil
+	}
+	var err error
+	if onlyFields, err = keyinfo.ParseFields(fields); err != nil {
+		return usageError("invalid --fields (%v)", err)
+	}
+	return nil
+}
+
+func strictFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("strict", false, "fail if any stored field is not exactly the derived value, instead of filling the missing fields and ignoring the eth address casing")
+}
+
+func networkFlag(fs *flag.FlagSet) *string {
+	return fs.String("network", "", "network name instead of the [NETWORK-ID] arg ("+keyinfo.NetworkNames()+")")
+}
+
+// resolveNetwork returns the network ID of the --network name, or of the
+// [NETWORK-ID] arg ("" if omitted). Both must agree if both are set.
+func resolveNetwork(name string, arg string) (uint32, error) {
+	if name == "" {
+		if arg == "" {
+			return 0, usageError("no [NETWORK-ID] arg or --network given")
+		}
+		return parseNetworkID(arg)
+	}
+	networkID, err := keyinfo.NetworkIDFromName(name)
+	if err != nil {
+		return 0, usageError("invalid --network (%v)", err)
+	}
+	if arg != "" {
+		argID, err := parseNetworkID(arg)
+		if err != nil {
+			return 0, err
+		}
+		if argID != networkID {
+			return 0, usageError("--network %s (%d) conflicts with the network ID %d", name, networkID, argID)
+		}
+	}
+	return networkID, nil
+}
+
+// optionalArg returns the i-th arg, or "" if omitted.
+func optionalArg(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
 }
 
-func encodeShortAddr2(pk *crypto.PrivateKeySECP256K1R) string {
-	pubAddr := pk.PublicKey().Address()
-	return pubAddr.String()
+// parseNetworkID parses the network ID argument.
+func parseNetworkID(s string) (uint32, error) {
+	networkID, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, usageError("invalid network ID %q (%v)", s, err)
+	}
+	return uint32(networkID), nil
 }
 
-func encodeAddr(pk *crypto.PrivateKeySECP256K1R, chainIDAlias string, hrp string) (string, error) {
-	pubBytes := pk.PublicKey().Address().Bytes()
-	return formatting.FormatAddress(chainIDAlias, hrp, pubBytes)
+// parseFlags parses the flags in args, allowing them to be interleaved
+// with the positional arguments, and returns the positional arguments.
+// e.g., "generate 9999 out.key.json --force"
+// The args after "--" are positional, even if they look like flags.
+// The global flags (see globalFlags) are parsed and applied with the flags of the mode.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	for _, f := range globalFlags {
+		if fs.Lookup(f.name) == nil {
+			fs.Var(f, f.name, f.usage)
+		}
+	}
+	args, terminated := splitTerminator(fs, args)
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, &exitError{code: exitCodeUsage, err: err}
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	showSecret := false
+	if f := fs.Lookup("show-secret"); f != nil {
+		showSecret = f.Value.String() == "true"
+	}
+	if err := applyGlobalFlags(showSecret); err != nil {
+		return nil, err
+	}
+	return append(positional, terminated...), nil
 }
 
-func encodeEthAddr(pk *crypto.PrivateKeySECP256K1R) string {
-	ethAddr := eth_crypto.PubkeyToAddress(pk.ToECDSA().PublicKey)
-	return ethAddr.String()
+// splitTerminator splits the args at the first "--" that is not the value
+// of a flag of the flag set, and returns the args before and after it.
+func splitTerminator(fs *flag.FlagSet, args []string) ([]string, []string) {
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			return args[:i], args[i+1:]
+		}
+		name, _, hasValue := splitFlag(args[i])
+		if name == "" || hasValue {
+			continue
+		}
+		if f := fs.Lookup(name); f != nil {
+			if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !bf.IsBoolFlag() {
+				i++
+			}
+		}
+	}
+	return args, nil
 }
@@ -0,0 +1,113 @@
+package keyinfo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcutil/bech32"
+)
+
+// Bech32 checksum variants. Avalanche chain addresses are always bech32,
+// and bech32m (BIP350) only differs in the checksum constant. No network
+// upgrade changed the address encoding (through apricot-phase-5, and the
+// upgrades activate by the timestamp of each network, not a block height),
+// so the derivation takes no fork or height.
+// ref. https://github.com/bitcoin/bips/blob/master/bip-0350.mediawiki
+const (
+	EncodingBech32  = "bech32"
+	EncodingBech32m = "bech32m"
+)
+
+// ErrBech32m is returned when a bech32m address is given where
+// the Avalanche bech32 address is expected.
+var ErrBech32m = errors.New("bech32m address where bech32 is expected (Avalanche chain addresses use the BIP173 bech32 checksum)")
+
+const (
+	bech32Const       = 1
+	bech32mConst      = 0x2bc830a3
+	bech32ChecksumLen = 6
+)
+
+// Bech32Variant returns the checksum variant of the bech32 string
+// (e.g., "avax1..." without the chain alias), detected from the checksum
+// constant, since the data part is encoded the same in both variants.
+func Bech32Variant(s string) (string, error) {
+	hrp, data, err := splitBech32(s)
+	if err != nil {
+		return "", err
+	}
+	switch bech32Polymod(hrp, data) {
+	case bech32Const:
+		return EncodingBech32, nil
+	case bech32mConst:
+		return EncodingBech32m, nil
+	}
+	return "", fmt.Errorf("invalid bech32 and bech32m checksum in %q", s)
+}
+
+// decodeBech32m decodes the 8-bit data of the bech32m string,
+// whose checksum must already be verified by Bech32Variant.
+func decodeBech32m(s string) (string, []byte, error) {
+	hrp, data, err := splitBech32(s)
+	if err != nil {
+		return "", nil, err
+	}
+	b, err := bech32.ConvertBits(data[:len(data)-bech32ChecksumLen], 5, 8, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return hrp, b, nil
+}
+
+// splitBech32 returns the lowercase HRP and the 5-bit data values
+// (including the checksum) of the bech32 or bech32m string.
+func splitBech32(s string) (string, []byte, error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, fmt.Errorf("mixed case in bech32 string %q", s)
+	}
+	s = strings.ToLower(s)
+	pos := strings.LastIndexByte(s, '1')
+	if pos < 1 || pos+bech32ChecksumLen+1 > len(s) {
+		return "", nil, fmt.Errorf("invalid bech32 separator position in %q", s)
+	}
+	hrp := s[:pos]
+	data := make([]byte, 0, len(s)-pos-1)
+	for _, c := range s[pos+1:] {
+		i := strings.IndexRune(bech32Charset, c)
+		if i < 0 {
+			return "", nil, fmt.Errorf("invalid bech32 character %q in %q", c, s)
+		}
+		data = append(data, byte(i))
+	}
+	return hrp, data, nil
+}
+
+// bech32Polymod is the BIP173 checksum over the expanded HRP and the data,
+// which is 1 for bech32 and 0x2bc830a3 for bech32m.
+func bech32Polymod(hrp string, data []byte) int {
+	values := make([]int, 0, len(hrp)*2+1+len(data))
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, int(hrp[i]>>5))
+	}
+	values = append(values, 0)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, int(hrp[i]&31))
+	}
+	for _, d := range data {
+		values = append(values, int(d))
+	}
+
+	gen := []int{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := 1
+	for _, v := range values {
+		b := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ v
+		for i := 0; i < 5; i++ {
+			if (b>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
@@ -0,0 +1,96 @@
+package keyinfo
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/utils/crypto"
+)
+
+const benchmarkPrivateKey = "PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN"
+
+// benchmarkNetworkIDs are the 50 network IDs of the --networks benchmarks,
+// including the mainnet, fuji, and local ones.
+func benchmarkNetworkIDs() []uint32 {
+	networkIDs := make([]uint32, 0, 50)
+	for i := uint32(1); len(networkIDs) < cap(networkIDs); i++ {
+		networkIDs = append(networkIDs, i)
+	}
+	networkIDs[len(networkIDs)-1] = 12345
+	return networkIDs
+}
+
+func benchmarkKey(b *testing.B) *crypto.PrivateKeySECP256K1R {
+	pk, err := DecodePrivateKey(benchmarkPrivateKey)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return pk
+}
+
+func BenchmarkEncodeNetworksAddrsSerial(b *testing.B) {
+	pk, networkIDs := benchmarkKey(b), benchmarkNetworkIDs()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, networkID := range networkIDs {
+			if _, err := EncodeNetworkAddrs(pk, networkID, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkEncodeNetworksAddrsParallel(b *testing.B) {
+	pk, networkIDs := benchmarkKey(b), benchmarkNetworkIDs()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := EncodeNetworksAddrs(pk, networkIDs, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodePrivateKey(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodePrivateKey(benchmarkPrivateKey); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeAddr(b *testing.B) {
+	pk := benchmarkKey(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := EncodeAddr(pk, "X", "avax"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeEthAddr(b *testing.B) {
+	pk := benchmarkKey(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		EncodeEthAddr(pk)
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	pk := benchmarkKey(b)
+	ki, err := NewInfoFromPrivateKey(pk, 9999)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := Validate(ki, 9999); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
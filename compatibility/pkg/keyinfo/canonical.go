@@ -0,0 +1,128 @@
+package keyinfo
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrCanonicalizeWatchOnly is returned when canonicalizing a watch-only
+// key file, which has no private key to re-derive the fields from.
+var ErrCanonicalizeWatchOnly = errors.New("cannot canonicalize a watch-only key file (no private key)")
+
+// Canonicalize validates the key info, and returns its canonical form:
+// every field re-derived from the private key (the current private key
+// prefix, the lowercase private key hex and bech32 addresses, and the EIP-55
+// eth addresses), with the additional networks and eth accounts sorted.
+// The cosmetic differences that do not change the key (e.g., the address
+// case, the missing optional fields) are normalized before the validation,
+// so the semantically identical key files have the same canonical form.
+func Canonicalize(ki Info, networkID uint32, hrp string) (Info, error) {
+	if ki.WatchOnly {
+		return Info{}, ErrCanonicalizeWatchOnly
+	}
+	ki = normalizeCase(ki)
+	if err := validate(ki, networkID, hrp, false, nil); err != nil {
+		return Info{}, err
+	}
+	pk, err := DecodePrivateKey(ki.PrivateKey)
+	if err != nil {
+		return Info{}, err
+	}
+	canonical, err := rederive(ki, pk, networkID, hrp)
+	if err != nil {
+		return Info{}, err
+	}
+	sort.SliceStable(canonical.Networks, func(i, j int) bool {
+		return canonical.Networks[i].NetworkID < canonical.Networks[j].NetworkID
+	})
+	if canonical.EthAccounts != nil {
+		accounts := make([]EthAccount, len(canonical.EthAccounts))
+		copy(accounts, canonical.EthAccounts)
+		sort.SliceStable(accounts, func(i, j int) bool { return accounts[i].Index < accounts[j].Index })
+		canonical.EthAccounts = accounts
+	}
+	if canonical.HDAccounts != nil {
+		accounts := make([]HDAccount, len(canonical.HDAccounts))
+		copy(accounts, canonical.HDAccounts)
+		sort.SliceStable(accounts, func(i, j int) bool { return accounts[i].Index < accounts[j].Index })
+		canonical.HDAccounts = accounts
+	}
+	return canonical, nil
+}
+
+// normalizeCase returns a copy of the key info with the bech32 part of the
+// chain addresses lowercased, and the eth addresses in the EIP-55 form.
+// Bech32 is case-insensitive (but not mixed-case), and so is the eth address.
+func normalizeCase(ki Info) Info {
+	ki.PrivateKeyHex = strings.ToLower(ki.PrivateKeyHex)
+	ki.XAddress = lowerBech32(ki.XAddress)
+	ki.PAddress = lowerBech32(ki.PAddress)
+	ki.CAddress = lowerBech32(ki.CAddress)
+	ki.EthAddress = checksumEthAddr(ki.EthAddress)
+	ki.Addresses = lowerBech32Map(ki.Addresses)
+	if ki.Networks != nil {
+		networks := make([]NetworkAddresses, len(ki.Networks))
+		for i, n := range ki.Networks {
+			n.HRP = strings.ToLower(n.HRP)
+			n.XAddress = lowerBech32(n.XAddress)
+			n.PAddress = lowerBech32(n.PAddress)
+			n.CAddress = lowerBech32(n.CAddress)
+			n.Addresses = lowerBech32Map(n.Addresses)
+			networks[i] = n
+		}
+		ki.Networks = networks
+	}
+	if ki.EthAccounts != nil {
+		accounts := make([]EthAccount, len(ki.EthAccounts))
+		for i, a := range ki.EthAccounts {
+			a.EthAddress = checksumEthAddr(a.EthAddress)
+			accounts[i] = a
+		}
+		ki.EthAccounts = accounts
+	}
+	if ki.HDAccounts != nil {
+		accounts := make([]HDAccount, len(ki.HDAccounts))
+		for i, a := range ki.HDAccounts {
+			a.XAddress = lowerBech32(a.XAddress)
+			a.PAddress = lowerBech32(a.PAddress)
+			a.CAddress = lowerBech32(a.CAddress)
+			a.EthAddress = checksumEthAddr(a.EthAddress)
+			accounts[i] = a
+		}
+		ki.HDAccounts = accounts
+	}
+	return ki
+}
+
+// lowerBech32 lowercases the bech32 part of the chain address,
+// keeping the chain alias (e.g., "X-", or a case-sensitive chain ID) as is.
+func lowerBech32(addr string) string {
+	idx := strings.Index(addr, "-")
+	if idx < 0 {
+		return addr
+	}
+	return addr[:idx+1] + strings.ToLower(addr[idx+1:])
+}
+
+func lowerBech32Map(addrs map[string]string) map[string]string {
+	if addrs == nil {
+		return nil
+	}
+	lowered := make(map[string]string, len(addrs))
+	for alias, addr := range addrs {
+		lowered[alias] = lowerBech32(addr)
+	}
+	return lowered
+}
+
+// checksumEthAddr returns the EIP-55 form of the eth address,
+// or the address as is if it is not a valid hex address.
+func checksumEthAddr(addr string) string {
+	if !common.IsHexAddress(addr) {
+		return addr
+	}
+	return common.HexToAddress(addr).Hex()
+}
@@ -0,0 +1,65 @@
+package keyinfo
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/utils/formatting"
+)
+
+// Duplicate is a pair of keys that are the same key (e.g., the same private
+// key copied to the key files of two "different" nodes).
+type Duplicate struct {
+	// First is the first key of the same key, and Second is the duplicate.
+	First  string
+	Second string
+	// Reason is what the keys share (e.g., "same private key").
+	Reason string
+}
+
+// DuplicateIndex finds the keys that share the private key bytes
+// (regardless of the private key encoding), or the X-chain address
+// (regardless of the chain alias, HRP, and case, e.g., for the watch-only keys).
+type DuplicateIndex struct {
+	privateKeys map[string]string
+	xAddresses  map[string]string
+}
+
+// NewDuplicateIndex returns the empty duplicate index.
+func NewDuplicateIndex() *DuplicateIndex {
+	return &DuplicateIndex{
+		privateKeys: make(map[string]string),
+		xAddresses:  make(map[string]string),
+	}
+}
+
+// Add indexes the key under the name (e.g., the key file path), and returns
+// the duplicate if a key added before is the same key. Each duplicate is
+// paired with the first key added, so a key copied to n files is n-1 pairs.
+func (idx *DuplicateIndex) Add(name string, ki Info) (Duplicate, bool, error) {
+	var skHex string
+	if !ki.WatchOnly {
+		pk, err := DecodePrivateKey(ki.PrivateKey)
+		if err != nil {
+			return Duplicate{}, false, err
+		}
+		skHex = hex.EncodeToString(pk.Bytes())
+	}
+	_, _, addr, err := formatting.ParseAddress(ki.XAddress)
+	if err != nil {
+		return Duplicate{}, false, fmt.Errorf("invalid X-chain address %q (%v)", ki.XAddress, err)
+	}
+	addrHex := hex.EncodeToString(addr)
+
+	if first, ok := idx.privateKeys[skHex]; ok && skHex != "" {
+		return Duplicate{First: first, Second: name, Reason: "same private key"}, true, nil
+	}
+	if first, ok := idx.xAddresses[addrHex]; ok {
+		return Duplicate{First: first, Second: name, Reason: "same X-chain address"}, true, nil
+	}
+	if skHex != "" {
+		idx.privateKeys[skHex] = name
+	}
+	idx.xAddresses[addrHex] = name
+	return Duplicate{}, false, nil
+}
@@ -0,0 +1,132 @@
+package keyinfo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ethereum/go-ethereum/common"
+	eth_crypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// AddressEncoder encodes the public key hash of a key as an address of the
+// network (e.g., for a fork of Avalanche with its own address scheme).
+// Register the custom encoders with RegisterAddressEncoder.
+type AddressEncoder interface {
+	// Encode encodes the 20-byte public key hash (see PublicKeyHasher)
+	// as the address of the network ID.
+	Encode(pubHash []byte, networkID uint32) (string, error)
+}
+
+// PublicKeyHasher is implemented by the address encoders that encode another
+// hash of the public key than the Avalanche one (ripemd160 of sha256 of the
+// compressed public key, e.g., the eth encoder hashes with keccak256).
+type PublicKeyHasher interface {
+	// HashPublicKey returns the hash of the public key to encode.
+	HashPublicKey(pub *crypto.PublicKeySECP256K1R) ([]byte, error)
+}
+
+var (
+	addressEncodersMu sync.RWMutex
+	addressEncoders   = make(map[string]AddressEncoder)
+)
+
+func init() {
+	for _, alias := range []string{"X", "P", "C"} {
+		RegisterAddressEncoder(strings.ToLower(alias), chainAddressEncoder{chainIDAlias: alias})
+	}
+	RegisterAddressEncoder("eth", ethAddressEncoder{})
+}
+
+// RegisterAddressEncoder makes the address encoder available by the name
+// (case-insensitive), e.g., in an "init" function of the fork's package.
+// It panics if the name is empty or already registered, or the encoder is nil.
+func RegisterAddressEncoder(name string, enc AddressEncoder) {
+	addressEncodersMu.Lock()
+	defer addressEncodersMu.Unlock()
+	name = strings.ToLower(name)
+	if name == "" || enc == nil {
+		panic("keyinfo: RegisterAddressEncoder with an empty name or a nil encoder")
+	}
+	if _, dup := addressEncoders[name]; dup {
+		panic(fmt.Sprintf("keyinfo: RegisterAddressEncoder called twice for %q", name))
+	}
+	addressEncoders[name] = enc
+}
+
+// AddressEncoderByName returns the registered address encoder of the name
+// (case-insensitive).
+func AddressEncoderByName(name string) (AddressEncoder, error) {
+	addressEncodersMu.RLock()
+	defer addressEncodersMu.RUnlock()
+	enc, ok := addressEncoders[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown address encoder %q (expected one of %s)", name, addressEncoderNames())
+	}
+	return enc, nil
+}
+
+// AddressEncoderNames returns the registered address encoder names, sorted.
+func AddressEncoderNames() string {
+	addressEncodersMu.RLock()
+	defer addressEncodersMu.RUnlock()
+	return addressEncoderNames()
+}
+
+func addressEncoderNames() string {
+	names := make([]string, 0, len(addressEncoders))
+	for name := range addressEncoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// EncodeAddressWith encodes the address of the private key for the network ID
+// with the registered address encoder of the name.
+func EncodeAddressWith(pk *crypto.PrivateKeySECP256K1R, name string, networkID uint32) (string, error) {
+	enc, err := AddressEncoderByName(name)
+	if err != nil {
+		return "", err
+	}
+	pub := pk.PublicKey().(*crypto.PublicKeySECP256K1R)
+	pubHash := pub.Address().Bytes()
+	if h, ok := enc.(PublicKeyHasher); ok {
+		if pubHash, err = h.HashPublicKey(pub); err != nil {
+			return "", fmt.Errorf("address encoder %q failed to hash the public key (%w)", name, err)
+		}
+	}
+	addr, err := enc.Encode(pubHash, networkID)
+	if err != nil {
+		return "", fmt.Errorf("address encoder %q failed (%w)", name, err)
+	}
+	return addr, nil
+}
+
+// chainAddressEncoder is the built-in encoder of the X, P, and C-chain
+// addresses, with the HRP of the network ID (e.g., "X-avax1...").
+type chainAddressEncoder struct {
+	chainIDAlias string
+}
+
+func (e chainAddressEncoder) Encode(pubHash []byte, networkID uint32) (string, error) {
+	return FormatAddress(e.chainIDAlias, constants.GetHRP(networkID), pubHash)
+}
+
+// ethAddressEncoder is the built-in encoder of the EIP-55 checksummed
+// Ethereum address, which is the same for every network ID.
+type ethAddressEncoder struct{}
+
+func (ethAddressEncoder) HashPublicKey(pub *crypto.PublicKeySECP256K1R) ([]byte, error) {
+	return eth_crypto.PubkeyToAddress(*pub.ToECDSA()).Bytes(), nil
+}
+
+func (ethAddressEncoder) Encode(pubHash []byte, _ uint32) (string, error) {
+	if len(pubHash) != common.AddressLength {
+		return "", fmt.Errorf("expected %d-byte hash, got %d bytes", common.AddressLength, len(pubHash))
+	}
+	return common.BytesToAddress(pubHash).Hex(), nil
+}
@@ -0,0 +1,189 @@
+package keyinfo
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// EncryptedFile is the envelope of a passphrase-encrypted key file,
+// which wraps the key info file contents (JSON or YAML) as is.
+// The AES-256-GCM key is derived from the passphrase with scrypt.
+type EncryptedFile struct {
+	Version int    `json:"version"`
+	Cipher  string `json:"cipher"`
+	KDF     string `json:"kdf"`
+	ScryptN int    `json:"scrypt_n"`
+	ScryptR int    `json:"scrypt_r"`
+	ScryptP int    `json:"scrypt_p"`
+	// Salt, Nonce, and Ciphertext are hex-encoded.
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+const (
+	encryptedFileVersion = 1
+	encryptedFileCipher  = "aes-256-gcm"
+	encryptedFileKDF     = "scrypt"
+
+	// same as the "geth account new" standard scrypt parameters
+	encryptedFileScryptN = 1 << 18
+	encryptedFileScryptR = 8
+	encryptedFileScryptP = 1
+	// the largest scrypt parameters accepted on decryption, so that a crafted
+	// envelope cannot make the decryption run out of memory (128*N*r bytes)
+	// or run for hours (N*r*p, 8 times the parameters above)
+	maxEncryptedFileScryptN      = 1 << 20
+	maxEncryptedFileScryptMemory = 1 << 30
+	maxEncryptedFileScryptWork   = 1 << 24
+
+	encryptedFileSaltLen = 32
+	// the shortest salt accepted on decryption
+	minEncryptedFileSaltLen = 16
+)
+
+// ErrDecryptFailed is returned when the encrypted key file cannot be decrypted,
+// which is either a wrong passphrase or a tampered file (AES-GCM does not tell them apart).
+var ErrDecryptFailed = errors.New("could not decrypt the key file (wrong passphrase, or the file is corrupted)")
+
+// IsEncryptedFile returns true if the file contents is the encrypted key file envelope,
+// detected by its "ciphertext", "salt", and "nonce" fields.
+func IsEncryptedFile(b []byte) bool {
+	if !bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		return false
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return false
+	}
+	for _, k := range []string{"ciphertext", "salt", "nonce"} {
+		if _, ok := fields[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// EncryptFile encrypts the key file contents with the passphrase,
+// and returns the JSON envelope.
+func EncryptFile(plaintext []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, encryptedFileSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := newFileAEAD(passphrase, salt, encryptedFileScryptN, encryptedFileScryptR, encryptedFileScryptP)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	ef := EncryptedFile{
+		Version:    encryptedFileVersion,
+		Cipher:     encryptedFileCipher,
+		KDF:        encryptedFileKDF,
+		ScryptN:    encryptedFileScryptN,
+		ScryptR:    encryptedFileScryptR,
+		ScryptP:    encryptedFileScryptP,
+		Salt:       hex.EncodeToString(salt),
+		Nonce:      hex.EncodeToString(nonce),
+		Ciphertext: hex.EncodeToString(aead.Seal(nil, nonce, plaintext, nil)),
+	}
+	return json.MarshalIndent(ef, "", "    ")
+}
+
+// DecryptFile decrypts the encrypted key file envelope with the passphrase,
+// and returns the key file contents.
+func DecryptFile(b []byte, passphrase string) ([]byte, error) {
+	var ef EncryptedFile
+	if err := json.Unmarshal(b, &ef); err != nil {
+		return nil, fmt.Errorf("invalid encrypted key file (%w)", err)
+	}
+	if ef.Version != encryptedFileVersion {
+		return nil, fmt.Errorf("unsupported encrypted key file version %d (expected %d)", ef.Version, encryptedFileVersion)
+	}
+	if ef.Cipher != encryptedFileCipher || ef.KDF != encryptedFileKDF {
+		return nil, fmt.Errorf("unsupported encrypted key file cipher %q and kdf %q (expected %q and %q)", ef.Cipher, ef.KDF, encryptedFileCipher, encryptedFileKDF)
+	}
+	if err := checkScryptParams(ef.ScryptN, ef.ScryptR, ef.ScryptP); err != nil {
+		return nil, err
+	}
+	var salt, nonce, ciphertext []byte
+	for _, f := range []struct {
+		name string
+		hex  string
+		dst  *[]byte
+	}{
+		{"salt", ef.Salt, &salt},
+		{"nonce", ef.Nonce, &nonce},
+		{"ciphertext", ef.Ciphertext, &ciphertext},
+	} {
+		d, err := hex.DecodeString(f.hex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encrypted key file %s (%w)", f.name, err)
+		}
+		*f.dst = d
+	}
+	if len(salt) < minEncryptedFileSaltLen {
+		return nil, fmt.Errorf("invalid encrypted key file salt (got %d bytes, expected at least %d bytes)", len(salt), minEncryptedFileSaltLen)
+	}
+
+	aead, err := newFileAEAD(passphrase, salt, ef.ScryptN, ef.ScryptR, ef.ScryptP)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid encrypted key file nonce (got %d bytes, expected %d bytes)", len(nonce), aead.NonceSize())
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrDecryptFailed
+	}
+	return plaintext, nil
+}
+
+// checkScryptParams fails if the scrypt parameters of the envelope are not
+// positive, or take more memory or work than the limits.
+func checkScryptParams(n, r, p int) error {
+	if n <= 0 || r <= 0 || p <= 0 {
+		return fmt.Errorf("invalid encrypted key file scrypt parameters N=%d, r=%d, p=%d (expected positive)", n, r, p)
+	}
+	if n > maxEncryptedFileScryptN {
+		return fmt.Errorf("encrypted key file scrypt_n %d exceeds the limit %d", n, maxEncryptedFileScryptN)
+	}
+	// each bound is checked before the product, so it does not overflow
+	if r > maxEncryptedFileScryptMemory/128 || 128*int64(n)*int64(r) > maxEncryptedFileScryptMemory {
+		return fmt.Errorf("encrypted key file scrypt parameters N=%d, r=%d exceed the memory limit of %d bytes", n, r, maxEncryptedFileScryptMemory)
+	}
+	if p > maxEncryptedFileScryptWork || int64(n)*int64(r)*int64(p) > maxEncryptedFileScryptWork {
+		return fmt.Errorf("encrypted key file scrypt parameters N=%d, r=%d, p=%d exceed the work limit N*r*p %d", n, r, p, maxEncryptedFileScryptWork)
+	}
+	return nil
+}
+
+// newFileAEAD returns the AES-256-GCM cipher with the scrypt-derived key.
+func newFileAEAD(passphrase string, salt []byte, n, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, n, r, p, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted key file scrypt parameters (%w)", err)
+	}
+	defer func() {
+		for i := range key {
+			key[i] = 0
+		}
+	}()
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
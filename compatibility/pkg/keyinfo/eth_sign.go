@@ -0,0 +1,61 @@
+package keyinfo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	eth_crypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// EthSignMessage signs the message with the EIP-191 "\x19Ethereum Signed Message:\n"
+// prefix, same as "personal_sign" in MetaMask, returning the 65-byte
+// [r || s || v] signature with v in {27, 28}.
+// ref. https://eips.ethereum.org/EIPS/eip-191
+func EthSignMessage(pk *crypto.PrivateKeySECP256K1R, msg []byte) ([]byte, error) {
+	sig, err := eth_crypto.Sign(accounts.TextHash(msg), pk.ToECDSA())
+	if err != nil {
+		return nil, err
+	}
+	sig[eth_crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+// EthRecoverMessageSigner recovers the EIP-55 checksummed address of the
+// "personal_sign" signer. Both v in {0, 1} and {27, 28} are accepted.
+func EthRecoverMessageSigner(msg []byte, sig []byte) (string, error) {
+	if len(sig) != crypto.SECP256K1RSigLen {
+		return "", fmt.Errorf("invalid signature length %d bytes (expected %d bytes)", len(sig), crypto.SECP256K1RSigLen)
+	}
+	s := make([]byte, len(sig))
+	copy(s, sig)
+	if s[eth_crypto.RecoveryIDOffset] >= 27 {
+		s[eth_crypto.RecoveryIDOffset] -= 27
+	}
+	pub, err := eth_crypto.SigToPub(accounts.TextHash(msg), s)
+	if err != nil {
+		return "", err
+	}
+	return eth_crypto.PubkeyToAddress(*pub).String(), nil
+}
+
+// EncodeEthSignature encodes the signature in "0x"-prefixed hex.
+func EncodeEthSignature(sig []byte) string {
+	return hexutil.Encode(sig)
+}
+
+// DecodeEthSignature decodes the "0x"-prefixed hex signature.
+func DecodeEthSignature(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") {
+		s = "0x" + s
+	}
+	return hexutil.Decode(s)
+}
+
+// IsEthAddress returns true if the string is a hex Ethereum address.
+func IsEthAddress(s string) bool {
+	return common.IsHexAddress(s)
+}
@@ -0,0 +1,76 @@
+package keyinfo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fieldNames maps the short field names of ParseFields to the key file
+// fields, in the key file order.
+var fieldNames = []struct {
+	name   string
+	fields []string
+}{
+	{"hex", []string{"private_key_hex"}},
+	{"x", []string{"x_address"}},
+	{"p", []string{"p_address"}},
+	{"c", []string{"c_address"}},
+	{"short", []string{"short_address"}},
+	{"eth", []string{"eth_address"}},
+	{"pubkey", []string{"public_key_compressed", "public_key_uncompressed"}},
+	{"chains", []string{"addresses"}},
+	{"networks", []string{"networks"}},
+	{"eth-accounts", []string{"eth_accounts"}},
+	{"hd-accounts", []string{"hd_accounts"}},
+}
+
+// FieldNames returns the short field names that ParseFields accepts.
+func FieldNames() string {
+	names := make([]string, len(fieldNames))
+	for i, f := range fieldNames {
+		names[i] = f.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// ParseFields parses the comma-separated short field names (e.g., "x,eth",
+// case-insensitive) into the key file fields for ValidateFields.
+func ParseFields(s string) ([]string, error) {
+	var fields []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		found := false
+		for _, f := range fieldNames {
+			if f.name == name {
+				fields = append(fields, f.fields...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown field %q (expected one of %s)", name, FieldNames())
+		}
+	}
+	return fields, nil
+}
+
+// selected returns true if the field is compared, that is, "only" is nil
+// (every field) or has the field.
+func selected(only map[string]bool, field string) bool {
+	return only == nil || only[field]
+}
+
+// selectedFields returns the mismatches of the compared fields, by the
+// top-level field of each mismatch (e.g., "networks" for "networks[0].x_address").
+func selectedFields(fields []FieldMismatch, only map[string]bool) []FieldMismatch {
+	if only == nil {
+		return fields
+	}
+	var sel []FieldMismatch
+	for _, f := range fields {
+		if selected(only, f.Field[:strings.IndexAny(f.Field+"[", ".[")]) {
+			sel = append(sel, f)
+		}
+	}
+	return sel
+}
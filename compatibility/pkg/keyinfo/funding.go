@@ -0,0 +1,52 @@
+package keyinfo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/utils/constants"
+)
+
+// Faucet is the public faucet of a test network.
+type Faucet struct {
+	Name string
+	URL  string
+}
+
+// faucets maps the network ID to its public faucet.
+// Only the public test networks have one.
+var faucets = map[uint32]Faucet{
+	constants.FujiID: {Name: "Avalanche Fuji faucet", URL: "https://faucet.avax.network/"},
+}
+
+// FaucetFor returns the public faucet of the network, if any.
+func FaucetFor(networkID uint32) (Faucet, bool) {
+	f, ok := faucets[networkID]
+	return f, ok
+}
+
+// FundingInstructions returns the human-readable block that explains how to
+// fund the key on the network: where the funds come from, and which
+// address to paste for each chain.
+func FundingInstructions(ki Info, networkID uint32) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "how to fund this key on %s:\n", NetworkLabel(networkID))
+
+	faucet, ok := FaucetFor(networkID)
+	switch {
+	case ok:
+		fmt.Fprintf(&sb, "  1. open the %s: %s\n", faucet.Name, faucet.URL)
+	case networkID == constants.MainnetID:
+		sb.WriteString("  1. no faucet on mainnet, withdraw AVAX from an exchange or send it from another wallet\n")
+	case networkID == constants.LocalID:
+		sb.WriteString("  1. no faucet on the local network, send from the pre-funded ewoq key (see the ewoq mode)\n")
+	default:
+		sb.WriteString("  1. no public faucet for this network, fund the key in the genesis (see --genesis-alloc)\n")
+		sb.WriteString("     or send from a key the genesis already funds\n")
+	}
+	fmt.Fprintf(&sb, "  2. for the C-chain (EVM, e.g., MetaMask or Core), paste the eth address:\n       %s\n", ki.EthAddress)
+	fmt.Fprintf(&sb, "     for the X-chain, paste the X-chain address:\n       %s\n", ki.XAddress)
+	sb.WriteString("  3. to stake or validate, move the funds to the P-chain address with a\n")
+	fmt.Fprintf(&sb, "     cross-chain export and import (the faucets do not send to the P-chain):\n       %s\n", ki.PAddress)
+	return sb.String()
+}
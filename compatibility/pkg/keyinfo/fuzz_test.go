@@ -0,0 +1,115 @@
+//go:build go1.18
+// +build go1.18
+
+package keyinfo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// The fuzzers need the Go 1.18 native fuzzing, so they are only built with
+// a Go 1.18 or newer toolchain, while the module stays on Go 1.17:
+//
+//	go test -run xxx -fuzz FuzzPrivateKeyRoundTrip -fuzztime 30s ./pkg/keyinfo
+//	go test -run xxx -fuzz FuzzDecodePrivateKey -fuzztime 30s ./pkg/keyinfo
+//
+// The inputs they found are pinned in TestDecodePrivateKeyInvalid.
+
+// FuzzPrivateKeyRoundTrip checks that any valid 32-byte key encodes to
+// CB58 and hex, and decodes back to the same key.
+func FuzzPrivateKeyRoundTrip(f *testing.F) {
+	ewoq, err := hex.DecodeString("56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027")
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(ewoq)
+	f.Add(make([]byte, 32))
+	f.Add(bytes.Repeat([]byte{0xff}, 32))
+	f.Fuzz(func(t *testing.T, skBytes []byte) {
+		pk, err := toPrivateKey(skBytes)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidPrivateKey) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			return
+		}
+		enc, err := EncodePrivateKey(pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := DecodePrivateKey(enc)
+		if err != nil {
+			t.Fatalf("%q: %v", enc, err)
+		}
+		if !bytes.Equal(decoded.Bytes(), skBytes) {
+			t.Fatalf("%q decodes to another key", enc)
+		}
+		decoded, err = DecodePrivateKeyFromHex(hex.EncodeToString(skBytes))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded.Bytes(), skBytes) {
+			t.Fatal("the hex key decodes to another key")
+		}
+	})
+}
+
+// FuzzDecodePrivateKey checks that the CB58 and hex decoders never panic on
+// arbitrary strings, and that any key they accept re-encodes to a key that
+// decodes to the same bytes.
+func FuzzDecodePrivateKey(f *testing.F) {
+	for _, s := range []string{
+		"PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN",
+		"ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN",
+		"SecretKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN",
+		"0x56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027",
+		"PrivateKey-",
+		"PrivateKey-" + strings.Repeat("z", 200),
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		for _, decode := range []func(string) error{
+			func(s string) error {
+				pk, err := DecodePrivateKey(s)
+				if err != nil {
+					return nil
+				}
+				enc, err := EncodePrivateKey(pk)
+				if err != nil {
+					return err
+				}
+				again, err := DecodePrivateKey(enc)
+				if err != nil {
+					return err
+				}
+				if !bytes.Equal(again.Bytes(), pk.Bytes()) {
+					return errors.New("the re-encoded CB58 key decodes to another key")
+				}
+				return nil
+			},
+			func(s string) error {
+				pk, err := DecodePrivateKeyFromHex(s)
+				if err != nil {
+					return nil
+				}
+				again, err := DecodePrivateKeyFromHex(hex.EncodeToString(pk.Bytes()))
+				if err != nil {
+					return err
+				}
+				if !bytes.Equal(again.Bytes(), pk.Bytes()) {
+					return errors.New("the re-encoded hex key decodes to another key")
+				}
+				return nil
+			},
+		} {
+			if err := decode(s); err != nil {
+				t.Fatalf("%q: %v", s, err)
+			}
+		}
+	})
+}
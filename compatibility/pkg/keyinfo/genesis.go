@@ -0,0 +1,67 @@
+package keyinfo
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// DefaultGenesisBalance is the default balance in nAVAX, same as the
+// X-chain allocation of the ewoq key in "avalanchego/genesis/genesis_local.go".
+const DefaultGenesisBalance = 300000000000000000
+
+// nAVAXToWei is the number of wei in one nAVAX: AVAX has 9 decimals
+// on the X and P chains, and 18 decimals on the C-chain.
+var nAVAXToWei = big.NewInt(1000000000)
+
+// GenesisAlloc is the avalanchego genesis allocation fragment of a key.
+// "allocations" goes in the genesis file as is, and "alloc" goes in
+// the (JSON-encoded) "cChainGenesis".
+type GenesisAlloc struct {
+	Allocations []GenesisAllocation            `json:"allocations"`
+	Alloc       map[string]GenesisAccountAlloc `json:"alloc"`
+}
+
+// GenesisAllocation is the same as "genesis.UnparsedAllocation".
+// "initialAmount" is the X-chain UTXO, and each "unlockSchedule" amount is
+// a P-chain UTXO (unlocked if "locktime" is 0).
+// ref. https://github.com/ava-labs/avalanchego/blob/v1.7.8/genesis/unparsed_config.go
+type GenesisAllocation struct {
+	ETHAddr        string                `json:"ethAddr"`
+	AVAXAddr       string                `json:"avaxAddr"`
+	InitialAmount  uint64                `json:"initialAmount"`
+	UnlockSchedule []GenesisLockedAmount `json:"unlockSchedule"`
+}
+
+// GenesisLockedAmount is the same as "genesis.LockedAmount".
+type GenesisLockedAmount struct {
+	Amount   uint64 `json:"amount"`
+	Locktime uint64 `json:"locktime"`
+}
+
+// GenesisAccountAlloc is the C-chain genesis account with the "0x"-prefixed
+// hex balance in wei, same as "core.GenesisAccount" in coreth.
+type GenesisAccountAlloc struct {
+	Balance string `json:"balance"`
+}
+
+// NewGenesisAlloc funds the X, P, and C-chain addresses of the key with
+// the balance in nAVAX each.
+func NewGenesisAlloc(ki Info, balance uint64) GenesisAlloc {
+	wei := new(big.Int).Mul(new(big.Int).SetUint64(balance), nAVAXToWei)
+	return GenesisAlloc{
+		Allocations: []GenesisAllocation{
+			{
+				ETHAddr:        strings.ToLower(ki.EthAddress),
+				AVAXAddr:       ki.XAddress,
+				InitialAmount:  balance,
+				UnlockSchedule: []GenesisLockedAmount{{Amount: balance}},
+			},
+		},
+		Alloc: map[string]GenesisAccountAlloc{
+			// same as "genesis_local.go", without "0x"
+			strings.TrimPrefix(ki.EthAddress, "0x"): {Balance: hexutil.EncodeBig(wei)},
+		},
+	}
+}
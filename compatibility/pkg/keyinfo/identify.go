@@ -0,0 +1,85 @@
+package keyinfo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrUnrecognizedAddress is returned when the address is neither
+// a bech32 chain address, a CB58 short address, nor an eth address.
+var ErrUnrecognizedAddress = errors.New("unrecognized address format")
+
+const (
+	AddressTypeChain = "chain"
+	AddressTypeShort = "short"
+	AddressTypeEth   = "eth"
+)
+
+// AddressInfo is what IdentifyAddress found out about an address.
+type AddressInfo struct {
+	// Type is one of AddressTypeChain, AddressTypeShort, or AddressTypeEth.
+	Type string
+	// ChainIDAlias, HRP, and Encoding are only set for the chain addresses.
+	ChainIDAlias string
+	HRP          string
+	// Encoding is EncodingBech32, or EncodingBech32m, which is not
+	// a valid Avalanche address but is still decoded.
+	Encoding string
+	// Hash is the 20-byte address hash, which is ripemd160(sha256(public key))
+	// for the chain and short addresses, and the last 20 bytes of
+	// keccak256(public key) for the eth addresses.
+	Hash []byte
+}
+
+// IdentifyAddress classifies the address, and decodes its 20-byte hash.
+func IdentifyAddress(addr string) (AddressInfo, error) {
+	addr = strings.TrimSpace(addr)
+	if strings.HasPrefix(addr, "0x") || strings.HasPrefix(addr, "0X") {
+		if !common.IsHexAddress(addr) {
+			return AddressInfo{}, fmt.Errorf("%w (%q is not a 20-byte hex eth address)", ErrUnrecognizedAddress, addr)
+		}
+		return AddressInfo{Type: AddressTypeEth, Hash: common.HexToAddress(addr).Bytes()}, nil
+	}
+	if strings.Contains(addr, "-") {
+		chainIDAlias := addr[:strings.Index(addr, "-")]
+		rawAddr := addr[len(chainIDAlias)+1:]
+		if variant, err := Bech32Variant(rawAddr); err == nil && variant == EncodingBech32m {
+			hrp, b, err := decodeBech32m(rawAddr)
+			if err != nil {
+				return AddressInfo{}, fmt.Errorf("%w (%q is not a bech32m chain address: %v)", ErrUnrecognizedAddress, addr, err)
+			}
+			return AddressInfo{Type: AddressTypeChain, ChainIDAlias: chainIDAlias, HRP: hrp, Encoding: EncodingBech32m, Hash: b}, nil
+		}
+		chainIDAlias, hrp, b, err := formatting.ParseAddress(addr)
+		if err != nil {
+			return AddressInfo{}, fmt.Errorf("%w (%q is not a bech32 chain address: %v)", ErrUnrecognizedAddress, addr, err)
+		}
+		return AddressInfo{Type: AddressTypeChain, ChainIDAlias: chainIDAlias, HRP: hrp, Encoding: EncodingBech32, Hash: b}, nil
+	}
+	// short IDs are 20 bytes
+	if len(addr) > maxCB58Len(20) {
+		return AddressInfo{}, fmt.Errorf("%w (%q)", ErrUnrecognizedAddress, addr)
+	}
+	b, err := formatting.Decode(formatting.CB58, addr)
+	if err != nil || len(b) != 20 {
+		return AddressInfo{}, fmt.Errorf("%w (%q)", ErrUnrecognizedAddress, addr)
+	}
+	return AddressInfo{Type: AddressTypeShort, Hash: b}, nil
+}
+
+// HRPNetworkLabel returns the label of the network that reserves the HRP
+// (e.g., "mainnet (1)"), or describes the custom network.
+func HRPNetworkLabel(hrp string) string {
+	if networkID, ok := constants.NetworkHRPToNetworkID[hrp]; ok {
+		return NetworkLabel(networkID)
+	}
+	if hrp == constants.FallbackHRP {
+		return "custom (any non-standard network ID)"
+	}
+	return "unknown (non-standard HRP)"
+}
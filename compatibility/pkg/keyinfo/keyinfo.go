@@ -0,0 +1,738 @@
+// Package keyinfo implements the Avalanche key encoding and address derivation
+// shared by the compatibility tools.
+package keyinfo
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	eth_crypto "github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/sync/errgroup"
+)
+
+// keyFactory is shared by all goroutines, and is safe for concurrent use:
+// NewPrivateKey, ToPrivateKey, and ToPublicKey do not touch the factory
+// state, and the public key cache of RecoverHashPublicKey is an LRU
+// guarded by its own mutex. The keys the factory returns are NOT safe for
+// concurrent use, since they lazily cache the public key and its hash
+// (see EncodeNetworksAddrs), so each goroutine must decode its own key.
+// Verified with "go run -race" in "scripts/tests.compatibility.sh".
+var keyFactory = new(crypto.FactorySECP256K1R)
+
+// Info is the key information stored in a key file.
+type Info struct {
+	PrivateKey string `json:"private_key"`
+	// ref. https://github.com/ava-labs/subnet-cli/blob/5b69345a3fba534fb6969002f41c8d3e69026fed/internal/key/key.go#L238-L258
+	PrivateKeyHex string `json:"private_key_hex"`
+	XAddress      string `json:"x_address"`
+	PAddress      string `json:"p_address"`
+	CAddress      string `json:"c_address"`
+	ShortAddress  string `json:"short_address"`
+	EthAddress    string `json:"eth_address"`
+
+	// NetworkID is the network the key file is for (optional).
+	// If set, it must match the network ID the key file is validated against.
+	NetworkID uint32 `json:"network_id,omitempty"`
+	// WatchOnly is true if the key info is derived from the public key only
+	// (e.g., a hardware wallet xpub), without the private key fields.
+	WatchOnly bool `json:"watch_only,omitempty"`
+
+	// PublicKeyCompressed is the 33-byte compressed public key in hex (optional).
+	PublicKeyCompressed string `json:"public_key_compressed,omitempty"`
+	// PublicKeyUncompressed is the 65-byte uncompressed public key in hex (optional).
+	PublicKeyUncompressed string `json:"public_key_uncompressed,omitempty"`
+
+	// Addresses maps each additional chain alias to its address.
+	Addresses map[string]string `json:"addresses,omitempty"`
+	// Networks is the addresses for each additional network.
+	Networks []NetworkAddresses `json:"networks,omitempty"`
+	// EthAccounts is the eth accounts derived from the HD wallet seed (optional).
+	// They cannot be derived from the private key, so they are kept as is.
+	EthAccounts []EthAccount `json:"eth_accounts,omitempty"`
+	// HDAccounts is the Avalanche accounts derived from the HD wallet seed
+	// for a range of account indices (optional), kept as is like EthAccounts.
+	HDAccounts []HDAccount `json:"hd_accounts,omitempty"`
+}
+
+// Redacted returns a copy of the key info with the private keys redacted,
+// for display.
+func (ki Info) Redacted() Info {
+	ki.PrivateKey = RedactPrivateKey(ki.PrivateKey)
+	ki.PrivateKeyHex = RedactSecret(ki.PrivateKeyHex)
+	return ki
+}
+
+// RedactPrivateKey redacts the "PrivateKey-" prefixed private key,
+// keeping the prefix.
+func RedactPrivateKey(enc string) string {
+	if !strings.HasPrefix(enc, privKeyEncPfx) {
+		return RedactSecret(enc)
+	}
+	return privKeyEncPfx + RedactSecret(strings.TrimPrefix(enc, privKeyEncPfx))
+}
+
+// RedactSecret shows only the first and last 4 characters of the secret.
+func RedactSecret(s string) string {
+	if len(s) <= 8 {
+		return strings.Repeat("*", len(s))
+	}
+	return s[:4] + "..." + s[len(s)-4:]
+}
+
+// NetworkAddresses is the set of addresses for a network.
+type NetworkAddresses struct {
+	NetworkID uint32 `json:"network_id"`
+	HRP       string `json:"hrp"`
+	XAddress  string `json:"x_address"`
+	PAddress  string `json:"p_address"`
+	CAddress  string `json:"c_address"`
+
+	// Addresses maps each additional chain alias to its address.
+	Addresses map[string]string `json:"addresses,omitempty"`
+}
+
+// NewInfoFromPrivateKey derives all the key information from the private key
+// for the given network ID.
+func NewInfoFromPrivateKey(pk *crypto.PrivateKeySECP256K1R, networkID uint32) (Info, error) {
+	return NewInfoFromPrivateKeyWithHRP(pk, networkID, constants.GetHRP(networkID))
+}
+
+// NewInfoFromPrivateKeyWithHRP is NewInfoFromPrivateKey with the HRP
+// overridden (e.g., private deployments without a registered network ID).
+func NewInfoFromPrivateKeyWithHRP(pk *crypto.PrivateKeySECP256K1R, networkID uint32, hrp string) (Info, error) {
+	pkEncoded, err := EncodePrivateKey(pk)
+	if err != nil {
+		return Info{}, err
+	}
+	pkDecoded, err := DecodePrivateKey(pkEncoded)
+	if err != nil {
+		return Info{}, err
+	}
+	if !SecretEqual(pk.Bytes(), pkDecoded.Bytes()) {
+		return Info{}, fmt.Errorf("pk.Bytes %s != pkDecoded.Bytes %s", pk.Bytes(), pkDecoded.Bytes())
+	}
+
+	na, err := EncodeNetworkAddrsWithHRP(pk, networkID, hrp, nil)
+	if err != nil {
+		return Info{}, err
+	}
+	shortAddr := EncodeShortAddr(pk)
+	if addr2 := pk.PublicKey().Address().String(); shortAddr != addr2 {
+		return Info{}, fmt.Errorf("short address %s != %s", shortAddr, addr2)
+	}
+
+	return Info{
+		PrivateKey:    pkEncoded,
+		PrivateKeyHex: hex.EncodeToString(pk.Bytes()),
+		XAddress:      na.XAddress,
+		PAddress:      na.PAddress,
+		CAddress:      na.CAddress,
+		ShortAddress:  shortAddr,
+		EthAddress:    EncodeEthAddr(pk),
+	}, nil
+}
+
+// Validate decodes the private key in the key info, re-derives all the
+// key information for the given network ID, and checks that it matches.
+func Validate(ki Info, networkID uint32) error {
+	return ValidateWithHRP(ki, networkID, constants.GetHRP(networkID))
+}
+
+// ValidateWithHRP is Validate with the HRP overridden.
+// The optional fields missing in old key files are populated
+// from the private key, instead of failing the validation.
+func ValidateWithHRP(ki Info, networkID uint32, hrp string) error {
+	return validate(ki, networkID, hrp, false, nil)
+}
+
+// ValidateStrict is ValidateWithHRP with zero tolerance: every stored field
+// must be exactly the derived value, including the missing fields.
+func ValidateStrict(ki Info, networkID uint32, hrp string) error {
+	return validate(ki, networkID, hrp, true, nil)
+}
+
+// ValidateFields is ValidateStrict restricted to the named fields (see
+// ParseFields), ignoring the other stored fields. The private key must
+// still decode, and the key file network ID must still match.
+func ValidateFields(ki Info, networkID uint32, hrp string, fields []string) error {
+	only := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		only[f] = true
+	}
+	return validate(ki, networkID, hrp, true, only)
+}
+
+// validate compares the fields in "only", or every field if nil.
+func validate(ki Info, networkID uint32, hrp string, strict bool, only map[string]bool) error {
+	if ki.NetworkID != 0 && ki.NetworkID != networkID {
+		return fmt.Errorf("key file network_id %d does not match the network ID %d", ki.NetworkID, networkID)
+	}
+	if ki.WatchOnly {
+		return validateWatchOnly(ki, networkID, hrp, only)
+	}
+	pk, err := DecodePrivateKey(ki.PrivateKey)
+	if err != nil {
+		return err
+	}
+	if selected(only, "private_key_hex") {
+		if err := checkPrivateKeyHex(ki, pk); err != nil {
+			return err
+		}
+	}
+	derived, err := rederive(ki, pk, networkID, hrp)
+	if err != nil {
+		return err
+	}
+	if selected(only, "c_address") && selected(only, "eth_address") {
+		if err := checkCChainAccount(ki, derived); err != nil {
+			return err
+		}
+	}
+	if selected(only, "eth_address") {
+		if err := checkEthChecksum(ki.EthAddress, derived.EthAddress); err != nil {
+			return err
+		}
+	}
+	if !strict {
+		fillMissingFields(&ki, derived)
+	}
+	if fields := selectedFields(mismatchedFields(ki, derived, ""), only); len(fields) > 0 {
+		return &MismatchError{Fields: fields}
+	}
+	return nil
+}
+
+// MissingFields returns the names of the optional fields missing in the
+// key info, which old key files with only "private_key" may not have.
+func MissingFields(ki Info) []string {
+	if ki.WatchOnly {
+		return nil
+	}
+	var fields []string
+	if ki.PrivateKeyHex == "" {
+		fields = append(fields, "private_key_hex")
+	}
+	if ki.ShortAddress == "" {
+		fields = append(fields, "short_address")
+	}
+	if ki.EthAddress == "" {
+		fields = append(fields, "eth_address")
+	}
+	return fields
+}
+
+// rederive derives the key info from the private key, with the same
+// optional fields as the stored key info (e.g., the additional chains).
+func rederive(ki Info, pk *crypto.PrivateKeySECP256K1R, networkID uint32, hrp string) (Info, error) {
+	derived, err := NewInfoFromPrivateKeyWithHRP(pk, networkID, hrp)
+	if err != nil {
+		return Info{}, err
+	}
+	derived.NetworkID, derived.EthAccounts, derived.HDAccounts = ki.NetworkID, ki.EthAccounts, ki.HDAccounts
+	if ki.PublicKeyCompressed != "" || ki.PublicKeyUncompressed != "" {
+		derived.PublicKeyCompressed, derived.PublicKeyUncompressed, err = EncodePublicKeys(pk)
+		if err != nil {
+			return Info{}, err
+		}
+	}
+	if ki.Addresses != nil {
+		aliases := make([]string, 0, len(ki.Addresses))
+		for alias := range ki.Addresses {
+			aliases = append(aliases, alias)
+		}
+		derived.Addresses, err = EncodeAddrs(pk, aliases, hrp)
+		if err != nil {
+			return Info{}, err
+		}
+	}
+	if ki.Networks != nil {
+		derived.Networks = make([]NetworkAddresses, 0, len(ki.Networks))
+		for _, n := range ki.Networks {
+			var aliases []string
+			for alias := range n.Addresses {
+				aliases = append(aliases, alias)
+			}
+			na, err := EncodeNetworkAddrs(pk, n.NetworkID, aliases)
+			if err != nil {
+				return Info{}, err
+			}
+			derived.Networks = append(derived.Networks, na)
+		}
+	}
+	return derived, nil
+}
+
+// fillMissingFields populates the missing optional fields from the derived key info.
+func fillMissingFields(ki *Info, derived Info) {
+	if ki.PrivateKeyHex == "" {
+		ki.PrivateKeyHex = derived.PrivateKeyHex
+	}
+	if ki.ShortAddress == "" {
+		ki.ShortAddress = derived.ShortAddress
+	}
+	if ki.EthAddress == "" {
+		ki.EthAddress = derived.EthAddress
+	}
+}
+
+// ErrSplicedEthAddress is returned when the "c_address" is the key's but the
+// "eth_address" is not, that is, the two C-chain formats are not the same account.
+var ErrSplicedEthAddress = errors.New("eth_address is not the same C-chain account as c_address")
+
+// checkCChainAccount checks that the stored "c_address" and "eth_address"
+// derive from the same public key. The two differ in every character, since
+// "c_address" is the bech32 of ripemd160(sha256(public key)) and "eth_address"
+// is the last 20 bytes of keccak256(public key), so a spliced-in eth address
+// of another key is reported explicitly instead of as a field mismatch.
+func checkCChainAccount(ki Info, derived Info) error {
+	if ki.CAddress != derived.CAddress || ki.EthAddress == "" || strings.EqualFold(ki.EthAddress, derived.EthAddress) {
+		return nil
+	}
+	return fmt.Errorf("%w (c_address %s is the key's, but eth_address %s is not, expected %s)", ErrSplicedEthAddress, ki.CAddress, ki.EthAddress, derived.EthAddress)
+}
+
+// checkEthChecksum returns an error if the stored eth address is the derived
+// one but not in the EIP-55 mixed-case checksum form (e.g., lowercased by
+// the tool it was copied from), pointing to the checksummed address.
+// ref. https://eips.ethereum.org/EIPS/eip-55
+func checkEthChecksum(stored string, derived string) error {
+	if stored == derived || !strings.EqualFold(stored, derived) {
+		return nil
+	}
+	return fmt.Errorf("eth_address %q does not have the EIP-55 checksum casing (expected %q)", stored, derived)
+}
+
+// checkPrivateKeyHex checks that the "private_key_hex" is the same key
+// as the decoded "private_key", byte-for-byte.
+// If not, the field that does not match the short address is reported.
+func checkPrivateKeyHex(ki Info, pk *crypto.PrivateKeySECP256K1R) error {
+	if ki.PrivateKeyHex == "" {
+		return nil
+	}
+	hexBytes, err := hex.DecodeString(ki.PrivateKeyHex)
+	if err != nil {
+		return fmt.Errorf("invalid private_key_hex (%w)", err)
+	}
+	if SecretEqual(pk.Bytes(), hexBytes) {
+		return nil
+	}
+
+	field := "private_key_hex"
+	if len(hexBytes) == crypto.SECP256K1RSKLen {
+		if hexPk, err := toPrivateKey(hexBytes); err == nil && EncodeShortAddr(hexPk) == ki.ShortAddress {
+			field = "private_key"
+		}
+	}
+	return fmt.Errorf("private_key and private_key_hex are different keys (%s is inconsistent with short_address %s)", field, ki.ShortAddress)
+}
+
+// NewPrivateKey generates a new random private key.
+func NewPrivateKey() (*crypto.PrivateKeySECP256K1R, error) {
+	rpk, err := keyFactory.NewPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	pk, ok := rpk.(*crypto.PrivateKeySECP256K1R)
+	if !ok {
+		return nil, fmt.Errorf("invalid type %T", rpk)
+	}
+	return pk, nil
+}
+
+// DefaultPrivateKeyPrefix is the avalanchego prefix of the CB58 private key.
+const DefaultPrivateKeyPrefix = "PrivateKey-"
+
+// privKeyEncPfx is the prefix that both EncodePrivateKey and DecodePrivateKey
+// use, so that a single run never mixes the prefixes.
+var privKeyEncPfx = DefaultPrivateKeyPrefix
+
+// SetPrivateKeyPrefix overrides the CB58 private key prefix for the forks
+// and the tools that use a non-standard prefix. It is not safe for
+// concurrent use, and must be called before any key is encoded or decoded.
+func SetPrivateKeyPrefix(pfx string) error {
+	// '-' is not in the base58 alphabet, so it separates the prefix unambiguously
+	if len(pfx) < 2 || !strings.HasSuffix(pfx, "-") || strings.ContainsAny(pfx, " \t\r\n") {
+		return fmt.Errorf("invalid private key prefix %q (expected a prefix ending with '-' without spaces, e.g., %q)", pfx, DefaultPrivateKeyPrefix)
+	}
+	if pfx != privKeyEncPfx {
+		cb58PrivateKey.Store(compileCB58PrivateKey(pfx))
+	}
+	privKeyEncPfx = pfx
+	return nil
+}
+
+// PrivateKeyPrefix returns the CB58 private key prefix in use.
+func PrivateKeyPrefix() string {
+	return privKeyEncPfx
+}
+
+// EwoqPrivateKey is the well-known pre-funded test key of the local networks
+// (same as "artifacts/ewoq.key.json"). Never use it on public networks.
+// ref. https://github.com/ava-labs/avalanchego/blob/v1.7.8/genesis/genesis_local.go
+const EwoqPrivateKey = DefaultPrivateKeyPrefix + "ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN"
+
+// ErrPrivateKeyPrefix is returned when the private key has a prefix other than
+// the one in use (see SetPrivateKeyPrefix).
+var ErrPrivateKeyPrefix = errors.New("private key prefix mismatch")
+
+// ErrCorruptedPrivateKey is returned when the CB58 checksum of the private key
+// does not match, usually due to a typo or a truncated copy-paste.
+var ErrCorruptedPrivateKey = errors.New("CB58 checksum failed — the private key string is corrupted or truncated")
+
+// ErrInvalidPrivateKey is returned when the decoded key bytes are not
+// a SECP256K1R private key, which is the 32-byte big-endian secp256k1
+// scalar in the range [1, N-1] (e.g., a key of another curve, or a
+// truncated or padded encoding).
+var ErrInvalidPrivateKey = errors.New("invalid SECP256K1R private key")
+
+// EncodePrivateKey encodes the private key in the "PrivateKey-" prefixed CB58 format,
+// or with the prefix set by SetPrivateKeyPrefix.
+func EncodePrivateKey(pk *crypto.PrivateKeySECP256K1R) (string, error) {
+	privKeyRaw := pk.Bytes()
+	enc, err := formatting.EncodeWithChecksum(formatting.CB58, privKeyRaw)
+	if err != nil {
+		return "", err
+	}
+	return privKeyEncPfx + enc, nil
+}
+
+// DecodePrivateKey decodes the "PrivateKey-" prefixed CB58 private key,
+// or the one with the prefix set by SetPrivateKeyPrefix. The unprefixed
+// CB58 private key is accepted as well, but any other prefix is an error.
+func DecodePrivateKey(enc string) (*crypto.PrivateKeySECP256K1R, error) {
+	rawPk := strings.TrimPrefix(enc, privKeyEncPfx)
+	if i := strings.LastIndexByte(rawPk, '-'); len(rawPk) == len(enc) && i >= 0 {
+		return nil, fmt.Errorf("%w (got %q, expected %q)", ErrPrivateKeyPrefix, enc[:i+1], privKeyEncPfx)
+	}
+	// base58 decoding is quadratic in the input length
+	if max := maxCB58Len(crypto.SECP256K1RSKLen); len(rawPk) > max {
+		return nil, fmt.Errorf("%w (got %d characters, expected at most %d characters)", ErrInvalidPrivateKey, len(rawPk), max)
+	}
+	skBytes, err := formatting.Decode(formatting.CB58, rawPk)
+	if err != nil {
+		// avalanchego does not export the checksum errors
+		switch err.Error() {
+		case "invalid input checksum", "input string is smaller than the checksum size":
+			return nil, fmt.Errorf("%w (got %d characters)", ErrCorruptedPrivateKey, len(rawPk))
+		}
+		return nil, err
+	}
+	return toPrivateKey(skBytes)
+}
+
+// DecodePrivateKeyFromHex decodes the 32-byte hex-encoded private key,
+// with or without "0x" prefix (e.g., subnet-cli, ethers).
+func DecodePrivateKeyFromHex(h string) (*crypto.PrivateKeySECP256K1R, error) {
+	h = strings.TrimPrefix(strings.TrimSpace(h), "0x")
+	skBytes, err := hex.DecodeString(h)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex private key (%w)", err)
+	}
+	if len(skBytes) != crypto.SECP256K1RSKLen {
+		return nil, fmt.Errorf("invalid hex private key length %d bytes (expected %d bytes)", len(skBytes), crypto.SECP256K1RSKLen)
+	}
+	return toPrivateKey(skBytes)
+}
+
+// DecodePrivateKeyFromBase64 decodes the 32-byte base64-encoded private key
+// (e.g., a secret manager blob), in the standard or the URL-safe alphabet,
+// with or without padding.
+func DecodePrivateKeyFromBase64(b64 string) (*crypto.PrivateKeySECP256K1R, error) {
+	b64 = strings.TrimRight(strings.TrimSpace(b64), "=")
+	enc := base64.RawStdEncoding
+	if strings.ContainsAny(b64, "-_") {
+		enc = base64.RawURLEncoding
+	}
+	skBytes, err := enc.DecodeString(b64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 private key (%w)", err)
+	}
+	if len(skBytes) != crypto.SECP256K1RSKLen {
+		return nil, fmt.Errorf("invalid base64 private key length %d bytes (expected %d bytes)", len(skBytes), crypto.SECP256K1RSKLen)
+	}
+	return toPrivateKey(skBytes)
+}
+
+// toPrivateKey checks the key bytes before converting them, since the
+// factory accepts any bytes and reduces them modulo the curve order.
+func toPrivateKey(skBytes []byte) (*crypto.PrivateKeySECP256K1R, error) {
+	if len(skBytes) != crypto.SECP256K1RSKLen {
+		return nil, fmt.Errorf("%w (got %d bytes, expected %d bytes)", ErrInvalidPrivateKey, len(skBytes), crypto.SECP256K1RSKLen)
+	}
+	if !validScalar(skBytes) {
+		return nil, fmt.Errorf("%w (scalar out of range, expected 0 < key < secp256k1 N)", ErrInvalidPrivateKey)
+	}
+	rpk, err := keyFactory.ToPrivateKey(skBytes)
+	if err != nil {
+		return nil, err
+	}
+	privKey, ok := rpk.(*crypto.PrivateKeySECP256K1R)
+	if !ok {
+		return nil, fmt.Errorf("%w (got %T)", ErrInvalidPrivateKey, rpk)
+	}
+	return privKey, nil
+}
+
+// validScalar returns true if the big-endian scalar is in [1, N-1].
+func validScalar(skBytes []byte) bool {
+	d := new(big.Int).SetBytes(skBytes)
+	return d.Sign() > 0 && d.Cmp(eth_crypto.S256().Params().N) < 0
+}
+
+// EncodePublicKeys encodes the 33-byte compressed and the 65-byte
+// uncompressed public keys in hex.
+func EncodePublicKeys(pk *crypto.PrivateKeySECP256K1R) (string, string, error) {
+	compressed := pk.PublicKey().Bytes()
+	if len(compressed) != 33 || (compressed[0] != 0x02 && compressed[0] != 0x03) {
+		return "", "", fmt.Errorf("invalid compressed public key %x", compressed)
+	}
+	uncompressed := eth_crypto.FromECDSAPub(publicKeyECDSA(pk))
+	if len(uncompressed) != 65 || uncompressed[0] != 0x04 {
+		return "", "", fmt.Errorf("invalid uncompressed public key %x", uncompressed)
+	}
+	return hex.EncodeToString(compressed), hex.EncodeToString(uncompressed), nil
+}
+
+// EncodeShortAddr encodes the public key hash in CB58 with checksum.
+func EncodeShortAddr(pk *crypto.PrivateKeySECP256K1R) string {
+	pubBytes := pk.PublicKey().Address().Bytes()
+	str, _ := formatting.EncodeWithChecksum(formatting.CB58, pubBytes)
+	return str
+}
+
+// EncodeAddr encodes the public key hash as a bech32 address
+// for the chain alias and HRP (e.g., "X-avax1...").
+func EncodeAddr(pk *crypto.PrivateKeySECP256K1R, chainIDAlias string, hrp string) (string, error) {
+	pubBytes := pk.PublicKey().Address().Bytes()
+	return FormatAddress(chainIDAlias, hrp, pubBytes)
+}
+
+// FormatAddress is formatting.FormatAddress, and parses the address back
+// to check that it decodes to the same chain alias, HRP, and public key hash,
+// in case of a regression in the bech32 encoding.
+func FormatAddress(chainIDAlias string, hrp string, pubBytes []byte) (string, error) {
+	addr, err := formatting.FormatAddress(chainIDAlias, hrp, pubBytes)
+	if err != nil {
+		return "", err
+	}
+	parsedAlias, parsedHRP, parsedBytes, err := formatting.ParseAddress(addr)
+	if err != nil {
+		return "", fmt.Errorf("encoded address %q does not parse (%w)", addr, err)
+	}
+	if parsedAlias != chainIDAlias || parsedHRP != hrp || !bytes.Equal(parsedBytes, pubBytes) {
+		return "", fmt.Errorf("encoded address %q does not round-trip (got %s-%s with 0x%x, expected %s-%s with 0x%x)",
+			addr, parsedAlias, parsedHRP, parsedBytes, chainIDAlias, hrp, pubBytes)
+	}
+	return addr, nil
+}
+
+// EncodeAddrFor derives the address of the private key using the same
+// chain alias and HRP as the given address (e.g., "X-fuji1...").
+func EncodeAddrFor(pk *crypto.PrivateKeySECP256K1R, addr string) (string, error) {
+	if ai, err := IdentifyAddress(addr); err == nil && ai.Encoding == EncodingBech32m {
+		return "", fmt.Errorf("%w: %q", ErrBech32m, addr)
+	}
+	chainIDAlias, hrp, _, err := formatting.ParseAddress(addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse address %q (%w)", addr, err)
+	}
+	return EncodeAddr(pk, chainIDAlias, hrp)
+}
+
+// EncodeAddrs encodes the addresses for each chain alias, keyed by the alias.
+func EncodeAddrs(pk *crypto.PrivateKeySECP256K1R, chainIDAliases []string, hrp string) (map[string]string, error) {
+	addrs := make(map[string]string, len(chainIDAliases))
+	for _, alias := range chainIDAliases {
+		addr, err := EncodeAddr(pk, alias, hrp)
+		if err != nil {
+			return nil, err
+		}
+		addrs[alias] = addr
+	}
+	return addrs, nil
+}
+
+// EncodeNetworkAddrs encodes the X/P/C addresses, and the addresses for
+// each additional chain alias, using the HRP of the network ID.
+// Unknown network IDs use the "custom" HRP.
+func EncodeNetworkAddrs(pk *crypto.PrivateKeySECP256K1R, networkID uint32, chainIDAliases []string) (NetworkAddresses, error) {
+	return EncodeNetworkAddrsWithHRP(pk, networkID, constants.GetHRP(networkID), chainIDAliases)
+}
+
+// EncodeNetworkAddrsWithHRP is EncodeNetworkAddrs with the HRP overridden.
+func EncodeNetworkAddrsWithHRP(pk *crypto.PrivateKeySECP256K1R, networkID uint32, hrp string, chainIDAliases []string) (NetworkAddresses, error) {
+	xAddr, err := EncodeAddr(pk, "X", hrp)
+	if err != nil {
+		return NetworkAddresses{}, err
+	}
+	pAddr, err := EncodeAddr(pk, "P", hrp)
+	if err != nil {
+		return NetworkAddresses{}, err
+	}
+	cAddr, err := EncodeAddr(pk, "C", hrp)
+	if err != nil {
+		return NetworkAddresses{}, err
+	}
+	na := NetworkAddresses{
+		NetworkID: networkID,
+		HRP:       hrp,
+		XAddress:  xAddr,
+		PAddress:  pAddr,
+		CAddress:  cAddr,
+	}
+	if len(chainIDAliases) > 0 {
+		na.Addresses, err = EncodeAddrs(pk, chainIDAliases, hrp)
+		if err != nil {
+			return NetworkAddresses{}, err
+		}
+	}
+	return na, nil
+}
+
+// EncodeNetworksAddrs is EncodeNetworkAddrs for each network ID, deriving
+// each (chain, network) pair in its own goroutine. The results are in the
+// order of the network IDs, regardless of the goroutine completion order.
+func EncodeNetworksAddrs(pk *crypto.PrivateKeySECP256K1R, networkIDs []uint32, chainIDAliases []string) ([]NetworkAddresses, error) {
+	// avalanchego lazily caches the public key and its hash,
+	// so compute them once before sharing with the goroutines
+	pubBytes := pk.PublicKey().Address().Bytes()
+
+	chains := append([]string{"X", "P", "C"}, chainIDAliases...)
+	addrs := make([][]string, len(networkIDs))
+	var g errgroup.Group
+	for i, networkID := range networkIDs {
+		addrs[i] = make([]string, len(chains))
+		hrp := constants.GetHRP(networkID)
+		for j, chain := range chains {
+			i, j, chain := i, j, chain
+			g.Go(func() error {
+				addr, err := FormatAddress(chain, hrp, pubBytes)
+				if err != nil {
+					return err
+				}
+				addrs[i][j] = addr
+				return nil
+			})
+		}
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	nas := make([]NetworkAddresses, len(networkIDs))
+	for i, networkID := range networkIDs {
+		nas[i] = NetworkAddresses{
+			NetworkID: networkID,
+			HRP:       constants.GetHRP(networkID),
+			XAddress:  addrs[i][0],
+			PAddress:  addrs[i][1],
+			CAddress:  addrs[i][2],
+		}
+		if len(chainIDAliases) > 0 {
+			nas[i].Addresses = make(map[string]string, len(chainIDAliases))
+			for j, alias := range chainIDAliases {
+				nas[i].Addresses[alias] = addrs[i][3+j]
+			}
+		}
+	}
+	return nas, nil
+}
+
+// ValidateHRP checks that the HRP is lowercase and bech32-legal,
+// that is, 1 to 83 printable US-ASCII characters.
+// ref. https://github.com/bitcoin/bips/blob/master/bip-0173.mediawiki
+func ValidateHRP(hrp string) error {
+	if len(hrp) < 1 || len(hrp) > 83 {
+		return fmt.Errorf("invalid HRP length %d (expected 1 to 83 characters)", len(hrp))
+	}
+	for _, c := range hrp {
+		if c < 33 || c > 126 {
+			return fmt.Errorf("invalid HRP character %q in %q", c, hrp)
+		}
+		if c >= 'A' && c <= 'Z' {
+			return fmt.Errorf("invalid HRP %q (must be lowercase)", hrp)
+		}
+	}
+	return nil
+}
+
+// ParseChainAliases parses the comma-separated chain aliases (e.g., "X,P,C,mychain").
+// Each may also be the CB58 chain ID instead of an alias (e.g., for a subnet
+// chain without an alias in the local node), which must decode to 32 bytes.
+func ParseChainAliases(s string) ([]string, error) {
+	var aliases []string
+	seen := make(map[string]bool)
+	for _, alias := range strings.Split(s, ",") {
+		alias = strings.TrimSpace(alias)
+		switch {
+		case alias == "":
+			return nil, fmt.Errorf("empty chain alias in %q", s)
+		case strings.ContainsAny(alias, "- \t"):
+			return nil, fmt.Errorf("invalid chain alias %q (must not contain '-' or whitespace)", alias)
+		case seen[alias]:
+			return nil, fmt.Errorf("duplicate chain alias %q in %q", alias, s)
+		case IsChainIDLike(alias):
+			if _, err := ids.FromString(alias); err != nil {
+				return nil, fmt.Errorf("invalid chain ID %q (must be the CB58 encoding of 32 bytes: %v)", alias, err)
+			}
+		}
+		seen[alias] = true
+		aliases = append(aliases, alias)
+	}
+	return aliases, nil
+}
+
+// chainIDLikeMinLen is the length from which an alias is taken as a chain ID.
+// The CB58 encoding of a 32-byte chain ID with the 4-byte checksum is ~49
+// characters, or 37 for the all-zero P-chain ID (one "1" per leading zero
+// byte), while aliases are much shorter (e.g., "X", "C").
+const chainIDLikeMinLen = 32
+
+// IsChainIDLike returns true if the chain alias looks like a CB58 chain ID
+// rather than a short alias, that is, long and only Base58 characters.
+func IsChainIDLike(alias string) bool {
+	if len(alias) < chainIDLikeMinLen {
+		return false
+	}
+	for _, c := range alias {
+		if !strings.ContainsRune(base58Alphabet, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// maxCB58Len returns the input length limit for the CB58 encoding of n bytes
+// with the 4-byte checksum, so that the decoders reject the long inputs
+// before the quadratic base58 decoding. It is twice the longest encoding
+// (~1.366 characters per byte), so that a few extra bytes still decode
+// and get the precise length error.
+func maxCB58Len(n int) int {
+	return (n + 4) * 2 * 1366 / 1000
+}
+
+// base58Alphabet is the Bitcoin Base58 alphabet used by CB58.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// EncodeEthAddr returns the EIP-55 checksummed Ethereum address.
+func EncodeEthAddr(pk *crypto.PrivateKeySECP256K1R) string {
+	ethAddr := eth_crypto.PubkeyToAddress(*publicKeyECDSA(pk))
+	return ethAddr.String()
+}
+
+// publicKeyECDSA returns the public key cached in the private key.
+// "pk.ToECDSA().PublicKey" would redo the scalar multiplication,
+// which dominates the key info derivation.
+func publicKeyECDSA(pk *crypto.PrivateKeySECP256K1R) *ecdsa.PublicKey {
+	return pk.PublicKey().(*crypto.PublicKeySECP256K1R).ToECDSA()
+}
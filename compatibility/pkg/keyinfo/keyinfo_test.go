@@ -0,0 +1,291 @@
+package keyinfo
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	eth_crypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// knownKeysCSV pins the addresses of the known test keys (the first of
+// "artifacts/test.insecure.secp256k1.keys", including ewoq) on the mainnet,
+// fuji, local, and custom network IDs.
+//
+//go:embed testdata/known_keys.csv
+var knownKeysCSV []byte
+
+type knownKey struct {
+	privateKey    string
+	privateKeyHex string
+	networkID     uint32
+	xAddress      string
+	pAddress      string
+	cAddress      string
+	ethAddress    string
+	shortAddress  string
+}
+
+func loadKnownKeys(t testing.TB) []knownKey {
+	records, err := csv.NewReader(bytes.NewReader(knownKeysCSV)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []knownKey
+	for _, r := range records[1:] {
+		networkID, err := strconv.ParseUint(r[2], 10, 32)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, knownKey{
+			privateKey:    r[0],
+			privateKeyHex: r[1],
+			networkID:     uint32(networkID),
+			xAddress:      r[3],
+			pAddress:      r[4],
+			cAddress:      r[5],
+			ethAddress:    r[6],
+			shortAddress:  r[7],
+		})
+	}
+	return keys
+}
+
+func TestKnownKeyAddresses(t *testing.T) {
+	for _, tv := range loadKnownKeys(t) {
+		tv := tv
+		t.Run(tv.privateKey[:20]+"/"+strconv.FormatUint(uint64(tv.networkID), 10), func(t *testing.T) {
+			pk, err := DecodePrivateKey(tv.privateKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ki, err := NewInfoFromPrivateKey(pk, tv.networkID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range []struct {
+				name, got, expected string
+			}{
+				{"x_address", ki.XAddress, tv.xAddress},
+				{"p_address", ki.PAddress, tv.pAddress},
+				{"c_address", ki.CAddress, tv.cAddress},
+				{"eth_address", ki.EthAddress, tv.ethAddress},
+				{"short_address", ki.ShortAddress, tv.shortAddress},
+				{"private_key_hex", ki.PrivateKeyHex, tv.privateKeyHex},
+			} {
+				if f.got != f.expected {
+					t.Errorf("%s: expected %q, got %q", f.name, f.expected, f.got)
+				}
+			}
+			if err := Validate(ki, tv.networkID); err != nil {
+				t.Errorf("derived key info fails validation (%v)", err)
+			}
+		})
+	}
+}
+
+func TestKnownKeyCB58RoundTrip(t *testing.T) {
+	for _, tv := range loadKnownKeys(t) {
+		pk, err := DecodePrivateKey(tv.privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc, err := EncodePrivateKey(pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if enc != tv.privateKey {
+			t.Errorf("CB58 round-trip: expected %q, got %q", tv.privateKey, enc)
+		}
+	}
+}
+
+func TestKnownKeyHexCB58Equivalence(t *testing.T) {
+	for _, tv := range loadKnownKeys(t) {
+		fromCB58, err := DecodePrivateKey(tv.privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, h := range []string{tv.privateKeyHex, "0x" + tv.privateKeyHex} {
+			fromHex, err := DecodePrivateKeyFromHex(h)
+			if err != nil {
+				t.Fatalf("%q: %v", h, err)
+			}
+			if !bytes.Equal(fromHex.Bytes(), fromCB58.Bytes()) {
+				t.Errorf("%q decodes to another key than %q", h, tv.privateKey)
+			}
+			ki, err := NewInfoFromPrivateKey(fromHex, tv.networkID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ki.PrivateKey != tv.privateKey || ki.XAddress != tv.xAddress {
+				t.Errorf("%q: expected %q and %q, got %q and %q", h, tv.privateKey, tv.xAddress, ki.PrivateKey, ki.XAddress)
+			}
+		}
+	}
+}
+
+// TestDecodePrivateKeyInvalid covers the bad inputs found by fuzzing the
+// decoders (see fuzz_test.go), which must fail without a panic or a stall.
+func TestDecodePrivateKeyInvalid(t *testing.T) {
+	const ewoq = "PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN"
+	cb58 := func(b []byte) string {
+		enc, err := formatting.EncodeWithChecksum(formatting.CB58, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "PrivateKey-" + enc
+	}
+	zero := make([]byte, 32)
+	curveN := eth_crypto.S256().Params().N.Bytes()
+
+	tt := []struct {
+		name   string
+		decode func(string) error
+		input  string
+		// expected is the expected error, or nil for any error
+		expected error
+	}{
+		{"empty", decodeCB58, "", ErrInvalidPrivateKey},
+		{"prefix only", decodeCB58, "PrivateKey-", ErrInvalidPrivateKey},
+		{"other prefix", decodeCB58, "SecretKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN", ErrPrivateKeyPrefix},
+		{"lowercase prefix", decodeCB58, "privatekey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN", ErrPrivateKeyPrefix},
+		{"bad checksum", decodeCB58, ewoq[:len(ewoq)-1] + "M", ErrCorruptedPrivateKey},
+		{"truncated", decodeCB58, ewoq[:len(ewoq)-5], ErrCorruptedPrivateKey},
+		{"not base58", decodeCB58, "PrivateKey-0OIl", nil},
+		{"long", decodeCB58, "PrivateKey-" + strings.Repeat("z", 200000), ErrInvalidPrivateKey},
+		{"31 bytes", decodeCB58, cb58(zero[1:]), ErrInvalidPrivateKey},
+		{"zero scalar", decodeCB58, cb58(zero), ErrInvalidPrivateKey},
+		{"curve order scalar", decodeCB58, cb58(curveN), ErrInvalidPrivateKey},
+		{"hex empty", decodeHex, "", nil},
+		{"hex odd length", decodeHex, "56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d802", nil},
+		{"hex not hex", decodeHex, strings.Repeat("zz", 32), nil},
+		{"hex long", decodeHex, strings.Repeat("ab", 100000), nil},
+		{"hex 31 bytes", decodeHex, hex.EncodeToString(zero[1:]), nil},
+		{"hex zero scalar", decodeHex, hex.EncodeToString(zero), ErrInvalidPrivateKey},
+		{"hex curve order scalar", decodeHex, "0x" + hex.EncodeToString(curveN), ErrInvalidPrivateKey},
+	}
+	for _, tv := range tt {
+		err := tv.decode(tv.input)
+		if err == nil {
+			t.Errorf("%s: expected an error, got none", tv.name)
+			continue
+		}
+		if tv.expected != nil && !errors.Is(err, tv.expected) {
+			t.Errorf("%s: expected %v, got %v", tv.name, tv.expected, err)
+		}
+	}
+}
+
+func decodeCB58(s string) error {
+	_, err := DecodePrivateKey(s)
+	return err
+}
+
+func decodeHex(s string) error {
+	_, err := DecodePrivateKeyFromHex(s)
+	return err
+}
+
+func TestRedactingWriter(t *testing.T) {
+	const (
+		ewoqBody   = "ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN"
+		ewoqHex    = "56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027"
+		ewoqPubkey = "0327448e78ffa8cdb24cf19be0204ad954b1bdb4db8c51183534c1eecf2ebd094e"
+	)
+	tt := []struct {
+		name string
+		// prefix is the SetPrivateKeyPrefix prefix, if not the default
+		prefix   string
+		writes   []string
+		expected string
+	}{
+		{
+			"private key",
+			"",
+			[]string{"private_key: PrivateKey-" + ewoqBody + "\n"},
+			"private_key: PrivateKey-ewoq...XtNN\n",
+		},
+		{
+			"private key split across writes",
+			"",
+			[]string{"private_key: PrivateKey-ewoqjP7PxY4yr3iLTp", "Lisriqt94hdyDFNgchSxGGztUrTXtNN\nnext", " line\n"},
+			"private_key: PrivateKey-ewoq...XtNN\nnext line\n",
+		},
+		{
+			"partial line on flush",
+			"",
+			[]string{"PrivateKey-", ewoqBody},
+			"PrivateKey-ewoq...XtNN",
+		},
+		{
+			"64-character hex",
+			"",
+			[]string{"private_key_hex: " + ewoqHex + "\n"},
+			"private_key_hex: 5628...8027\n",
+		},
+		{
+			"0x-prefixed hex",
+			"",
+			[]string{"\"0x" + ewoqHex + "\"\n"},
+			"\"0x5628...8027\"\n",
+		},
+		{
+			"66-character hex",
+			"",
+			[]string{"public_key_compressed: " + ewoqPubkey + "\n"},
+			"public_key_compressed: " + ewoqPubkey + "\n",
+		},
+		{
+			"64-character hex in a longer word",
+			"",
+			[]string{"x" + ewoqHex + "\n"},
+			"x" + ewoqHex + "\n",
+		},
+		{
+			"custom prefix",
+			"SecretKey-",
+			[]string{"SecretKey-" + ewoqBody + " PrivateKey-" + ewoqBody + "\n"},
+			"SecretKey-ewoq...XtNN PrivateKey-ewoq...XtNN\n",
+		},
+		{
+			"other prefix",
+			"",
+			[]string{"SecretKey-" + ewoqBody + "\n"},
+			"SecretKey-" + ewoqBody + "\n",
+		},
+	}
+	for _, tv := range tt {
+		t.Run(tv.name, func(t *testing.T) {
+			if tv.prefix != "" {
+				if err := SetPrivateKeyPrefix(tv.prefix); err != nil {
+					t.Fatal(err)
+				}
+				defer SetPrivateKeyPrefix(DefaultPrivateKeyPrefix)
+			}
+			var b bytes.Buffer
+			rw := NewRedactingWriter(&b)
+			for _, w := range tv.writes {
+				n, err := rw.Write([]byte(w))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if n != len(w) {
+					t.Fatalf("expected %d bytes written, got %d", len(w), n)
+				}
+			}
+			if err := rw.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tv.expected {
+				t.Errorf("expected %q, got %q", tv.expected, b.String())
+			}
+		})
+	}
+}
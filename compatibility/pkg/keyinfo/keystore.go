@@ -0,0 +1,64 @@
+package keyinfo
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	eth_crypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+// EncryptKeystore encrypts the private key with the passphrase in the
+// Web3 Secret Storage (V3 keystore) JSON format, using the standard scrypt
+// parameters, same as "geth account new".
+// ref. https://github.com/ethereum/wiki/wiki/Web3-Secret-Storage-Definition
+func EncryptKeystore(pk *crypto.PrivateKeySECP256K1R, passphrase string) ([]byte, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	ecdsaKey := pk.ToECDSA()
+	defer zeroECDSA(ecdsaKey)
+
+	key := &keystore.Key{
+		Id:         id,
+		Address:    eth_crypto.PubkeyToAddress(ecdsaKey.PublicKey),
+		PrivateKey: ecdsaKey,
+	}
+	return keystore.EncryptKey(key, passphrase, keystore.StandardScryptN, keystore.StandardScryptP)
+}
+
+// DecryptKeystore decrypts the V3 keystore JSON with the passphrase,
+// and checks that the decrypted key matches the keystore address.
+func DecryptKeystore(b []byte, passphrase string) (*crypto.PrivateKeySECP256K1R, error) {
+	key, err := keystore.DecryptKey(b, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroECDSA(key.PrivateKey)
+
+	var hdr struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(b, &hdr); err != nil {
+		return nil, err
+	}
+	if hdr.Address != "" && !strings.EqualFold(strings.TrimPrefix(hdr.Address, "0x"), strings.TrimPrefix(key.Address.Hex(), "0x")) {
+		return nil, fmt.Errorf("keystore address %q != decrypted key address %s", hdr.Address, key.Address.Hex())
+	}
+
+	// not zeroed, the returned private key keeps the bytes
+	return toPrivateKey(eth_crypto.FromECDSA(key.PrivateKey))
+}
+
+// zeroECDSA clears the private key scalar in memory.
+func zeroECDSA(k *ecdsa.PrivateKey) {
+	b := k.D.Bits()
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,67 @@
+package keyinfo
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// AddressIndex maps the address hashes of a set of keys to the key names,
+// to find which key owns an address regardless of its chain alias and HRP.
+// The chain and short addresses share the ripemd160(sha256) hash, and the
+// eth addresses are indexed separately by their keccak256 hash.
+type AddressIndex struct {
+	keys map[string][]string
+}
+
+// NewAddressIndex returns the empty address index.
+func NewAddressIndex() *AddressIndex {
+	return &AddressIndex{keys: make(map[string][]string)}
+}
+
+// Add indexes the addresses of the key under the name (e.g., the key file path).
+// The addresses are derived from the private key, or taken from the stored
+// short and eth addresses for the watch-only keys.
+func (idx *AddressIndex) Add(name string, ki Info) error {
+	shortAddr, ethAddr := ki.ShortAddress, ki.EthAddress
+	if !ki.WatchOnly {
+		pk, err := DecodePrivateKey(ki.PrivateKey)
+		if err != nil {
+			return err
+		}
+		shortAddr, ethAddr = EncodeShortAddr(pk), EncodeEthAddr(pk)
+	}
+	for _, addr := range []string{shortAddr, ethAddr} {
+		k, err := addressIndexKey(addr)
+		if err != nil {
+			return err
+		}
+		idx.keys[k] = append(idx.keys[k], name)
+	}
+	return nil
+}
+
+// Lookup returns the names of the keys that own the address,
+// or none if the address does not belong to any indexed key.
+func (idx *AddressIndex) Lookup(addr string) ([]string, error) {
+	k, err := addressIndexKey(addr)
+	if err != nil {
+		return nil, err
+	}
+	return idx.keys[k], nil
+}
+
+// addressIndexKey returns the index key of the address,
+// which is the address hash prefixed by its hash kind.
+func addressIndexKey(addr string) (string, error) {
+	ai, err := IdentifyAddress(addr)
+	if err != nil {
+		return "", err
+	}
+	if ai.Encoding == EncodingBech32m {
+		return "", fmt.Errorf("%w: %q", ErrBech32m, addr)
+	}
+	if ai.Type == AddressTypeEth {
+		return "eth:" + hex.EncodeToString(ai.Hash), nil
+	}
+	return "avax:" + hex.EncodeToString(ai.Hash), nil
+}
@@ -0,0 +1,90 @@
+package keyinfo
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldMismatch is a key info field whose stored value differs from
+// the value derived from the private key.
+type FieldMismatch struct {
+	// Field is the key file field name (e.g., "eth_address", "networks[0].x_address").
+	Field   string
+	Stored  string
+	Derived string
+}
+
+// MismatchError is returned when the stored key info fields do not match
+// the derived ones.
+type MismatchError struct {
+	Fields []FieldMismatch
+}
+
+func (e *MismatchError) Error() string {
+	ss := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		ss[i] = fmt.Sprintf("%s (stored %q, derived %q)", f.Field, f.Stored, f.Derived)
+	}
+	return "key info does not match the private key: " + strings.Join(ss, ", ")
+}
+
+// mismatchedFields compares the stored and derived values of each field,
+// including the additional chain and network addresses.
+// The private key values are redacted.
+func mismatchedFields(stored interface{}, derived interface{}, prefix string) []FieldMismatch {
+	sv, dv := reflect.ValueOf(stored), reflect.ValueOf(derived)
+	var fields []FieldMismatch
+	for i := 0; i < sv.NumField(); i++ {
+		name := prefix + strings.Split(sv.Type().Field(i).Tag.Get("json"), ",")[0]
+		sf, df := sv.Field(i), dv.Field(i)
+		switch sf.Kind() {
+		case reflect.Map:
+			keys := make(map[string]struct{})
+			for _, k := range append(sf.MapKeys(), df.MapKeys()...) {
+				keys[k.String()] = struct{}{}
+			}
+			sorted := make([]string, 0, len(keys))
+			for k := range keys {
+				sorted = append(sorted, k)
+			}
+			sort.Strings(sorted)
+			for _, k := range sorted {
+				s, d := sf.MapIndex(reflect.ValueOf(k)), df.MapIndex(reflect.ValueOf(k))
+				if !s.IsValid() || !d.IsValid() || s.String() != d.String() {
+					fields = append(fields, FieldMismatch{Field: fmt.Sprintf("%s[%s]", name, k), Stored: mapValue(s), Derived: mapValue(d)})
+				}
+			}
+		case reflect.Slice:
+			if sf.Len() != df.Len() {
+				fields = append(fields, FieldMismatch{Field: name, Stored: fmt.Sprintf("%d entries", sf.Len()), Derived: fmt.Sprintf("%d entries", df.Len())})
+				continue
+			}
+			for j := 0; j < sf.Len(); j++ {
+				fields = append(fields, mismatchedFields(sf.Index(j).Interface(), df.Index(j).Interface(), fmt.Sprintf("%s[%d].", name, j))...)
+			}
+		default:
+			secret := name == "private_key" || name == "private_key_hex"
+			if secret && SecretEqual([]byte(sf.String()), []byte(df.String())) {
+				continue
+			}
+			if !secret && reflect.DeepEqual(sf.Interface(), df.Interface()) {
+				continue
+			}
+			f := FieldMismatch{Field: name, Stored: fmt.Sprint(sf.Interface()), Derived: fmt.Sprint(df.Interface())}
+			if secret {
+				f.Stored, f.Derived = RedactSecret(f.Stored), RedactSecret(f.Derived)
+			}
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+func mapValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	return v.String()
+}
@@ -0,0 +1,220 @@
+package keyinfo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// ErrMnemonicNotSupported is returned when converting a private key to a mnemonic.
+// A mnemonic encodes the seed of the whole HD wallet, and the seed cannot be
+// recovered from a derived private key.
+var ErrMnemonicNotSupported = errors.New("private key cannot be converted to a mnemonic")
+
+// AvaxCoinType is the BIP44 coin type for Avalanche.
+// ref. https://github.com/satoshilabs/slips/blob/master/slip-0044.md
+const AvaxCoinType = 9000
+
+// EthCoinType is the BIP44 coin type for Ethereum, which Core and MetaMask
+// use to derive the C-chain eth accounts.
+const EthCoinType = 60
+
+// DerivationPath returns the standard Avalanche BIP44 path for the account index.
+func DerivationPath(accountIndex uint32) string {
+	return fmt.Sprintf("m/44'/%d'/0'/0/%d", AvaxCoinType, accountIndex)
+}
+
+// EthDerivationPath returns the standard Ethereum BIP44 path for the account index.
+func EthDerivationPath(accountIndex uint32) string {
+	return fmt.Sprintf("m/44'/%d'/0'/0/%d", EthCoinType, accountIndex)
+}
+
+// EthAccount is an eth address derived from the HD wallet seed.
+type EthAccount struct {
+	Index      uint32 `json:"index"`
+	Path       string `json:"path"`
+	EthAddress string `json:"eth_address"`
+}
+
+// DecodePrivateKeyFromMnemonic derives the private key from the BIP39 mnemonic
+// using the Avalanche derivation path "m/44'/9000'/0'/0/[accountIndex]",
+// which is the same path used by the Avalanche wallet and Core.
+func DecodePrivateKeyFromMnemonic(mnemonic string, accountIndex uint32) (*crypto.PrivateKeySECP256K1R, error) {
+	master, err := DecodeMasterKey(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	return DecodePrivateKeyFromMaster(master, accountIndex)
+}
+
+// DecodeMasterKey decodes the HD wallet master key from the BIP39 mnemonic,
+// or from the "xprv"-prefixed BIP32 root extended private key.
+func DecodeMasterKey(mnemonicOrXprv string) (*hdkeychain.ExtendedKey, error) {
+	if s := strings.TrimSpace(mnemonicOrXprv); strings.HasPrefix(s, "xprv") {
+		return decodeMasterXprv(s)
+	}
+	return decodeMasterMnemonic(mnemonicOrXprv)
+}
+
+func decodeMasterMnemonic(mnemonic string) (*hdkeychain.ExtendedKey, error) {
+	words := strings.Fields(mnemonic)
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return nil, fmt.Errorf("invalid mnemonic word count %d (expected 12, 15, 18, 21, or 24)", len(words))
+	}
+	for i, w := range words {
+		if _, ok := bip39.GetWordIndex(w); !ok {
+			return nil, fmt.Errorf("invalid mnemonic word %q at position %d (not in the BIP39 English word list)", w, i+1)
+		}
+	}
+
+	// all words are known, so the only remaining failure is the checksum
+	seed, err := bip39.NewSeedWithErrorChecking(strings.Join(words, " "), "")
+	if err != nil {
+		return nil, errors.New("invalid mnemonic checksum (wrong or misordered words?)")
+	}
+
+	return hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+}
+
+// decodeMasterXprv decodes the root extended private key, which derives
+// the same keys as the mnemonic of its seed.
+func decodeMasterXprv(xprv string) (*hdkeychain.ExtendedKey, error) {
+	master, err := hdkeychain.NewKeyFromString(xprv)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extended private key (%w)", err)
+	}
+	if !master.IsPrivate() {
+		return nil, errors.New("expected an extended private key (xprv), got an extended public key")
+	}
+	if master.Depth() != 0 {
+		return nil, fmt.Errorf("extended private key depth %d != 0 (expected the root key at \"m\")", master.Depth())
+	}
+	return master, nil
+}
+
+// DecodePrivateKeyFromMaster derives the private key at
+// "m/44'/9000'/0'/0/[accountIndex]" from the master key.
+func DecodePrivateKeyFromMaster(master *hdkeychain.ExtendedKey, accountIndex uint32) (*crypto.PrivateKeySECP256K1R, error) {
+	return derivePrivateKey(master, AvaxCoinType, accountIndex)
+}
+
+// DeriveEthAccounts derives the first n eth accounts at
+// "m/44'/60'/0'/0/[index]" from the master key, in index order.
+// These are the accounts Core and MetaMask list for the same seed,
+// and are different keys from the Avalanche path.
+func DeriveEthAccounts(master *hdkeychain.ExtendedKey, n uint32) ([]EthAccount, error) {
+	accounts := make([]EthAccount, 0, n)
+	for i := uint32(0); i < n; i++ {
+		pk, err := derivePrivateKey(master, EthCoinType, i)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, EthAccount{
+			Index:      i,
+			Path:       EthDerivationPath(i),
+			EthAddress: EncodeEthAddr(pk),
+		})
+	}
+	return accounts, nil
+}
+
+// HDAccount is the Avalanche account at an index of the HD wallet seed.
+type HDAccount struct {
+	Index      uint32 `json:"index"`
+	Path       string `json:"path"`
+	XAddress   string `json:"x_address"`
+	PAddress   string `json:"p_address"`
+	CAddress   string `json:"c_address"`
+	EthAddress string `json:"eth_address"`
+}
+
+// MaxHDRange is the maximum number of account indices in a ParseHDRange range.
+const MaxHDRange = 1000
+
+// ParseHDRange parses the inclusive account index range "[FIRST]-[LAST]"
+// (e.g., "0-9"), of at most MaxHDRange indices.
+func ParseHDRange(s string) (uint32, uint32, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid range %q (expected [FIRST]-[LAST], e.g., 0-9)", s)
+	}
+	var idx [2]uint32
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid range %q index %q (%v)", s, p, err)
+		}
+		idx[i] = uint32(n)
+	}
+	first, last := idx[0], idx[1]
+	if first > last {
+		return 0, 0, fmt.Errorf("invalid range %q (the first index is greater than the last)", s)
+	}
+	if uint64(last)-uint64(first)+1 > MaxHDRange {
+		return 0, 0, fmt.Errorf("range %q is %d indices (expected at most %d)", s, uint64(last)-uint64(first)+1, MaxHDRange)
+	}
+	return first, last, nil
+}
+
+// DeriveHDAccounts derives the accounts at "m/44'/9000'/0'/0/[index]" from
+// the master key for each index from first to last, in index order.
+func DeriveHDAccounts(master *hdkeychain.ExtendedKey, first uint32, last uint32, networkID uint32, hrp string) ([]HDAccount, error) {
+	accounts := make([]HDAccount, 0, last-first+1)
+	for i := uint64(first); i <= uint64(last); i++ {
+		pk, err := derivePrivateKey(master, AvaxCoinType, uint32(i))
+		if err != nil {
+			return nil, err
+		}
+		na, err := EncodeNetworkAddrsWithHRP(pk, networkID, hrp, nil)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, HDAccount{
+			Index:      uint32(i),
+			Path:       DerivationPath(uint32(i)),
+			XAddress:   na.XAddress,
+			PAddress:   na.PAddress,
+			CAddress:   na.CAddress,
+			EthAddress: EncodeEthAddr(pk),
+		})
+	}
+	return accounts, nil
+}
+
+// EncodePrivateKeyToMnemonic is not supported, and always returns ErrMnemonicNotSupported.
+func EncodePrivateKeyToMnemonic(pk *crypto.PrivateKeySECP256K1R) (string, error) {
+	return "", ErrMnemonicNotSupported
+}
+
+// derivePrivateKey derives "m/44'/[coinType]'/0'/0/[accountIndex]" from the master key.
+func derivePrivateKey(master *hdkeychain.ExtendedKey, coinType uint32, accountIndex uint32) (*crypto.PrivateKeySECP256K1R, error) {
+	key := master
+	for _, idx := range []uint32{
+		hdkeychain.HardenedKeyStart + 44,
+		hdkeychain.HardenedKeyStart + coinType,
+		hdkeychain.HardenedKeyStart + 0,
+		0,
+		accountIndex,
+	} {
+		var err error
+		key, err = key.Child(idx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ecPriv, err := key.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+
+	// always 32 bytes, ECPrivKey.Serialize pads the scalar
+	return toPrivateKey(ecPriv.Serialize())
+}
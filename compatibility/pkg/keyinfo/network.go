@@ -0,0 +1,111 @@
+package keyinfo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/utils/constants"
+)
+
+// IsStandardNetwork returns true if the network ID is one of the reserved
+// Avalanche networks (e.g., mainnet, fuji, local), which have their own HRP.
+// All other network IDs are custom networks with the "custom" HRP.
+func IsStandardNetwork(networkID uint32) bool {
+	_, ok := constants.NetworkIDToHRP[networkID]
+	return ok
+}
+
+// NetworkLabel returns the human readable label of the network ID
+// (e.g., "mainnet (1)", "local (12345)", "custom (9999)").
+func NetworkLabel(networkID uint32) string {
+	if name, ok := constants.NetworkIDToNetworkName[networkID]; ok {
+		return fmt.Sprintf("%s (%d)", name, networkID)
+	}
+	return fmt.Sprintf("custom (%d)", networkID)
+}
+
+// StandardNetworks returns the labels of the standard networks, sorted by ID.
+func StandardNetworks() string {
+	ids := make([]int, 0, len(constants.NetworkIDToHRP))
+	for id := range constants.NetworkIDToHRP {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+	labels := make([]string, len(ids))
+	for i, id := range ids {
+		labels[i] = NetworkLabel(uint32(id))
+	}
+	return strings.Join(labels, ", ")
+}
+
+// CheckNetworkHRP returns an error if the network ID is a standard network
+// but the HRP is not the one reserved for it.
+func CheckNetworkHRP(networkID uint32, hrp string) error {
+	expected, ok := constants.NetworkIDToHRP[networkID]
+	if !ok || hrp == expected {
+		return nil
+	}
+	return fmt.Errorf("network %s expects HRP %q, but got %q", NetworkLabel(networkID), expected, hrp)
+}
+
+// CheckFallbackHRP returns an error if the network ID is not a standard network,
+// so constants.GetHRP falls back to the "custom" HRP, but the recorded HRP
+// (e.g., of the key file addresses) is the one reserved for a standard network.
+// This is most likely a mistyped network ID (e.g., 50 for fuji (5)).
+func CheckFallbackHRP(networkID uint32, hrp string, recordedHRP string) error {
+	if IsStandardNetwork(networkID) || hrp != constants.FallbackHRP || recordedHRP == hrp {
+		return nil
+	}
+	recordedID, ok := constants.NetworkHRPToNetworkID[recordedHRP]
+	if !ok {
+		return nil
+	}
+	return fmt.Errorf("network ID %d is not a standard network and falls back to HRP %q, but the addresses use HRP %q of %s (use network ID %d, or --hrp %s)",
+		networkID, hrp, recordedHRP, NetworkLabel(recordedID), recordedID, recordedHRP)
+}
+
+// CheckMainnetReuse returns an error if exactly one of the HRP the key is
+// validated for and the recorded HRP (e.g., of the key file addresses) is the
+// mainnet HRP, so a mainnet key is not mistaken for the disposable testnet key
+// material, and a testnet key (e.g., shared in a test setup) is not funded on
+// mainnet.
+func CheckMainnetReuse(networkID uint32, hrp string, recordedHRP string) error {
+	if recordedHRP == hrp || (hrp != constants.MainnetHRP && recordedHRP != constants.MainnetHRP) {
+		return nil
+	}
+	if recordedHRP == constants.MainnetHRP {
+		return fmt.Errorf("MAINNET KEY: the addresses use the mainnet HRP %q, but the key is used for %s with HRP %q (do not reuse a mainnet key as testnet key material)",
+			recordedHRP, NetworkLabel(networkID), hrp)
+	}
+	return fmt.Errorf("TESTNET KEY ON MAINNET: the addresses use HRP %q, but the key is used for %s with HRP %q (do not fund a testnet key on mainnet, generate a new key instead)",
+		recordedHRP, NetworkLabel(networkID), hrp)
+}
+
+// NetworkIDFromName returns the network ID of the standard network name
+// (e.g., "mainnet", "fuji", "local"), case-insensitively.
+func NetworkIDFromName(name string) (uint32, error) {
+	networkID, ok := constants.NetworkNameToNetworkID[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown network name %q (expected one of %s)", name, NetworkNames())
+	}
+	return networkID, nil
+}
+
+// NetworkNames returns the accepted network names, sorted by network ID:
+// mainnet (1), cascade (2), denali (3), everest (4), fuji (5) and its alias
+// testnet (5), testing (10), and local (12345).
+func NetworkNames() string {
+	names := make([]string, 0, len(constants.NetworkNameToNetworkID))
+	for name := range constants.NetworkNameToNetworkID {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := constants.NetworkNameToNetworkID[names[i]], constants.NetworkNameToNetworkID[names[j]]
+		if a != b {
+			return a < b
+		}
+		return names[i] < names[j]
+	})
+	return strings.Join(names, ", ")
+}
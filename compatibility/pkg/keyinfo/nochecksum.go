@@ -0,0 +1,90 @@
+package keyinfo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/mr-tron/base58"
+)
+
+// NoChecksumWarning describes the risk of the non-checksummed encodings,
+// to show wherever they are printed.
+const NoChecksumWarning = "NON-STANDARD encoding without the CB58 checksum: a typo in it is not detected, and avalanchego and the wallets do not accept it"
+
+// EncodePrivateKeyNoChecksum encodes the private key in CB58 without the
+// 4-byte checksum, with the "PrivateKey-" prefix (see NoChecksumWarning).
+func EncodePrivateKeyNoChecksum(pk *crypto.PrivateKeySECP256K1R) (string, error) {
+	enc, err := formatting.EncodeWithoutChecksum(formatting.CB58, pk.Bytes())
+	if err != nil {
+		return "", err
+	}
+	return privKeyEncPfx + enc, nil
+}
+
+// EncodeShortAddrNoChecksum encodes the public key hash in CB58 without the
+// 4-byte checksum (see NoChecksumWarning).
+func EncodeShortAddrNoChecksum(pk *crypto.PrivateKeySECP256K1R) (string, error) {
+	return formatting.EncodeWithoutChecksum(formatting.CB58, pk.PublicKey().Address().Bytes())
+}
+
+// FromNoChecksum returns the key info with the "private_key" and
+// "short_address" in the standard CB58 encodings, if either is encoded
+// without the checksum. The two are told apart by the decoded length
+// (e.g., 32 bytes of a private key, or 36 bytes with the checksum), so each
+// field is accepted in either encoding.
+func FromNoChecksum(ki Info) (Info, error) {
+	if ki.PrivateKey != "" && !strings.HasPrefix(ki.PrivateKey, privKeyEncPfx) {
+		return Info{}, fmt.Errorf("%w (expected %q)", ErrPrivateKeyPrefix, privKeyEncPfx)
+	}
+	if raw := strings.TrimPrefix(ki.PrivateKey, privKeyEncPfx); raw != "" {
+		skBytes, ok, err := decodeNoChecksum(raw, crypto.SECP256K1RSKLen)
+		if err != nil {
+			return Info{}, fmt.Errorf("invalid private_key (%v)", err)
+		}
+		if ok {
+			pk, err := toPrivateKey(skBytes)
+			if err != nil {
+				return Info{}, err
+			}
+			if ki.PrivateKey, err = EncodePrivateKey(pk); err != nil {
+				return Info{}, err
+			}
+		}
+	}
+	if ki.ShortAddress != "" {
+		b, ok, err := decodeNoChecksum(ki.ShortAddress, len(ids.ShortEmpty))
+		if err != nil {
+			return Info{}, fmt.Errorf("invalid short_address %q (%v)", ki.ShortAddress, err)
+		}
+		if ok {
+			ki.ShortAddress, err = formatting.EncodeWithChecksum(formatting.CB58, b)
+			if err != nil {
+				return Info{}, err
+			}
+		}
+	}
+	return ki, nil
+}
+
+// decodeNoChecksum decodes the CB58 string, returning true if it is the
+// n bytes without the checksum, and false if it is the n bytes with the
+// checksum (left to the standard decoding).
+func decodeNoChecksum(s string, n int) ([]byte, bool, error) {
+	if len(s) > maxCB58Len(n) {
+		return nil, false, fmt.Errorf("got %d characters, expected at most %d characters", len(s), maxCB58Len(n))
+	}
+	b, err := base58.Decode(s)
+	if err != nil {
+		return nil, false, err
+	}
+	switch len(b) {
+	case n:
+		return b, true, nil
+	case n + 4:
+		return nil, false, nil
+	}
+	return nil, false, fmt.Errorf("got %d bytes, expected %d bytes without the checksum or %d bytes with it", len(b), n, n+4)
+}
@@ -0,0 +1,54 @@
+package keyinfo
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/staking"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+)
+
+// NodeIDFromCert returns the "NodeID-" prefixed ID of the staking TLS
+// certificate (DER), which is the ripemd160(sha256) hash of the certificate.
+// ref. config/config.go "getStakingTLSCertFromFile"
+func NodeIDFromCert(certDER []byte) (string, error) {
+	id, err := ids.ToShortID(hashing.PubkeyBytesToAddress(certDER))
+	if err != nil {
+		return "", err
+	}
+	return id.PrefixedString(constants.NodeIDPrefix), nil
+}
+
+// LoadNodeID loads the staking TLS key and certificate PEM files
+// (e.g., "staker.key" and "staker.crt"), and returns the NodeID of the certificate.
+// The files must be a valid pair, same as avalanchego loads them.
+func LoadNodeID(keyPath string, certPath string) (string, error) {
+	cert, err := staking.LoadTLSCertFromFiles(keyPath, certPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid staking key pair %q and %q (%w)", keyPath, certPath, err)
+	}
+	return NodeIDFromCert(cert.Leaf.Raw)
+}
+
+// ShortAddressToNodeID formats the CB58 short address as the "NodeID-"
+// prefixed ID. Both encode the same 20-byte hash, but a NodeID is the hash
+// of the staking TLS certificate, so the short address of a secp256k1 key
+// is never the NodeID of a node.
+func ShortAddressToNodeID(shortAddr string) (string, error) {
+	id, err := ids.ShortFromString(shortAddr)
+	if err != nil {
+		return "", fmt.Errorf("invalid short address %q (%w)", shortAddr, err)
+	}
+	return id.PrefixedString(constants.NodeIDPrefix), nil
+}
+
+// NodeIDToShortAddress returns the CB58 short address of the "NodeID-"
+// prefixed ID, the reverse of ShortAddressToNodeID.
+func NodeIDToShortAddress(nodeID string) (string, error) {
+	id, err := ids.ShortFromPrefixedString(nodeID, constants.NodeIDPrefix)
+	if err != nil {
+		return "", fmt.Errorf("invalid node ID %q (%w)", nodeID, err)
+	}
+	return id.String(), nil
+}
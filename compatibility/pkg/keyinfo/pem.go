@@ -0,0 +1,163 @@
+package keyinfo
+
+import (
+	"bytes"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	eth_crypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// PEM block types of the SEC1 and PKCS#8 private keys.
+const (
+	PEMTypeSEC1  = "EC PRIVATE KEY"
+	PEMTypePKCS8 = "PRIVATE KEY"
+)
+
+// crypto/x509 does not support secp256k1 (MarshalECPrivateKey fails with
+// an unknown curve), so the ASN.1 structures are encoded here.
+var (
+	// ref. https://www.rfc-editor.org/rfc/rfc5480#section-2.1.1
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	// ref. https://www.secg.org/sec2-v2.pdf (section A.2.1)
+	oidSECP256K1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// ErrPEMCurve is returned when the PEM key is not a secp256k1 key
+// (e.g., a P-256 TLS key).
+var ErrPEMCurve = errors.New("PEM key is not a secp256k1 key")
+
+// sec1PrivateKey is the SEC1 ECPrivateKey structure.
+// ref. https://www.rfc-editor.org/rfc/rfc5915#section-3
+type sec1PrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// pkcs8PrivateKey is the PKCS#8 PrivateKeyInfo structure.
+// ref. https://www.rfc-editor.org/rfc/rfc5208#section-5
+type pkcs8PrivateKey struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// EncodePEM encodes the private key as the SEC1 "EC PRIVATE KEY" PEM
+// (same as "openssl ecparam -name secp256k1 -genkey -noout"), or as the
+// PKCS#8 "PRIVATE KEY" PEM (same as "openssl pkcs8 -topk8 -nocrypt") if
+// pkcs8 is true. Both include the uncompressed public key.
+func EncodePEM(pk *crypto.PrivateKeySECP256K1R, pkcs8 bool) ([]byte, error) {
+	pub := eth_crypto.FromECDSAPub(publicKeyECDSA(pk))
+	sec1 := sec1PrivateKey{
+		Version:    1,
+		PrivateKey: pk.Bytes(),
+		PublicKey:  asn1.BitString{Bytes: pub, BitLength: 8 * len(pub)},
+	}
+	if !pkcs8 {
+		sec1.NamedCurveOID = oidSECP256K1
+		der, err := asn1.Marshal(sec1)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: PEMTypeSEC1, Bytes: der}), nil
+	}
+
+	// the curve is in the PKCS#8 algorithm parameters instead
+	inner, err := asn1.Marshal(sec1)
+	if err != nil {
+		return nil, err
+	}
+	params, err := asn1.Marshal(oidSECP256K1)
+	if err != nil {
+		return nil, err
+	}
+	der, err := asn1.Marshal(pkcs8PrivateKey{
+		Algo: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPublicKeyECDSA,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		PrivateKey: inner,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: PEMTypePKCS8, Bytes: der}), nil
+}
+
+// DecodePEM decodes the secp256k1 private key from the SEC1 or PKCS#8 PEM
+// (unencrypted), skipping the other blocks (e.g., "EC PARAMETERS" of
+// "openssl ecparam -genkey"). The public key, if any, must match the private key.
+func DecodePEM(b []byte) (*crypto.PrivateKeySECP256K1R, error) {
+	rest := bytes.TrimSpace(b)
+	for len(rest) > 0 {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		switch block.Type {
+		case PEMTypeSEC1:
+			return decodeSEC1(block.Bytes, nil)
+		case PEMTypePKCS8:
+			return decodePKCS8(block.Bytes)
+		case "ENCRYPTED PRIVATE KEY":
+			return nil, errors.New("encrypted PEM keys are not supported (decrypt with \"openssl pkcs8\" first)")
+		}
+	}
+	return nil, fmt.Errorf("no %q or %q PEM block found", PEMTypeSEC1, PEMTypePKCS8)
+}
+
+func decodePKCS8(der []byte) (*crypto.PrivateKeySECP256K1R, error) {
+	var p8 pkcs8PrivateKey
+	if _, err := asn1.Unmarshal(der, &p8); err != nil {
+		return nil, fmt.Errorf("invalid PKCS#8 private key (%w)", err)
+	}
+	if !p8.Algo.Algorithm.Equal(oidPublicKeyECDSA) {
+		return nil, fmt.Errorf("PKCS#8 private key algorithm %v is not EC", p8.Algo.Algorithm)
+	}
+	var curve asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(p8.Algo.Parameters.FullBytes, &curve); err != nil {
+		return nil, fmt.Errorf("invalid PKCS#8 EC parameters (%w)", err)
+	}
+	return decodeSEC1(p8.PrivateKey, curve)
+}
+
+// decodeSEC1 decodes the SEC1 private key, whose curve is either in the
+// structure itself, or in the enclosing PKCS#8 parameters.
+func decodeSEC1(der []byte, curve asn1.ObjectIdentifier) (*crypto.PrivateKeySECP256K1R, error) {
+	var sec1 sec1PrivateKey
+	if _, err := asn1.Unmarshal(der, &sec1); err != nil {
+		return nil, fmt.Errorf("invalid SEC1 private key (%w)", err)
+	}
+	if sec1.Version != 1 {
+		return nil, fmt.Errorf("unsupported SEC1 private key version %d", sec1.Version)
+	}
+	if len(sec1.NamedCurveOID) > 0 {
+		curve = sec1.NamedCurveOID
+	}
+	if !curve.Equal(oidSECP256K1) {
+		return nil, fmt.Errorf("%w (curve %v)", ErrPEMCurve, curve)
+	}
+	// the scalar may be stored without the leading zero bytes
+	if len(sec1.PrivateKey) > 32 {
+		return nil, fmt.Errorf("%w (got %d bytes, expected 32 bytes)", ErrInvalidPrivateKey, len(sec1.PrivateKey))
+	}
+	skBytes := make([]byte, 32)
+	copy(skBytes[32-len(sec1.PrivateKey):], sec1.PrivateKey)
+	pk, err := toPrivateKey(skBytes)
+	if err != nil {
+		return nil, err
+	}
+	// the public key is uncompressed, or compressed with "-conv_form compressed"
+	ecdsaPub := publicKeyECDSA(pk)
+	if pub := sec1.PublicKey.Bytes; len(pub) > 0 && !bytes.Equal(pub, eth_crypto.FromECDSAPub(ecdsaPub)) && !bytes.Equal(pub, eth_crypto.CompressPubkey(ecdsaPub)) {
+		return nil, errors.New("PEM public key does not match the private key")
+	}
+	return pk, nil
+}
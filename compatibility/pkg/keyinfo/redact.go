@@ -0,0 +1,110 @@
+package keyinfo
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"sync/atomic"
+)
+
+// cb58PrivateKeyBody matches the CB58 part of an encoded private key
+// (32 bytes and the 4-byte checksum is ~50 characters), but not the
+// already-redacted "PrivateKey-ewoq...XtNN" or the prefix alone.
+const cb58PrivateKeyBody = `[1-9A-HJ-NP-Za-km-z]{40,}`
+
+// cb58PrivateKey is the *regexp.Regexp of the CB58 private keys with the
+// default or current prefix. It is compiled once per prefix (see
+// SetPrivateKeyPrefix) instead of on every RedactSecrets call, and is
+// atomic since the redacted stdout and stderr are redacted concurrently.
+var cb58PrivateKey atomic.Value
+
+func init() {
+	cb58PrivateKey.Store(compileCB58PrivateKey(DefaultPrivateKeyPrefix))
+}
+
+// compileCB58PrivateKey compiles the regexp of the CB58 private keys with
+// the default prefix or pfx, capturing the prefix and the CB58 part.
+func compileCB58PrivateKey(pfx string) *regexp.Regexp {
+	prefixes := regexp.QuoteMeta(DefaultPrivateKeyPrefix)
+	if pfx != DefaultPrivateKeyPrefix {
+		prefixes += "|" + regexp.QuoteMeta(pfx)
+	}
+	return regexp.MustCompile(`(` + prefixes + `)(` + cb58PrivateKeyBody + `)`)
+}
+
+// hexRun matches a run of hex characters, optionally "0x"-prefixed,
+// which is a private key if it is exactly 64 characters on its own.
+var hexRun = regexp.MustCompile(`(?:0x)?[0-9a-fA-F]{64,}`)
+
+// RedactSecrets redacts anything in the text that looks like a private key:
+// the CB58 private keys with the default or current prefix (see
+// SetPrivateKeyPrefix), and the 64-character hex strings that are not part
+// of a longer word (e.g., not a 66-character compressed public key).
+// The redacted values are the same as RedactPrivateKey and RedactSecret.
+func RedactSecrets(s string) string {
+	cb58 := cb58PrivateKey.Load().(*regexp.Regexp)
+	s = cb58.ReplaceAllStringFunc(s, func(m string) string {
+		sub := cb58.FindStringSubmatch(m)
+		return sub[1] + RedactSecret(sub[2])
+	})
+
+	var b bytes.Buffer
+	last := 0
+	for _, loc := range hexRun.FindAllStringIndex(s, -1) {
+		start, end := loc[0], loc[1]
+		prefix := ""
+		if s[start:start+2] == "0x" {
+			prefix = "0x"
+		}
+		if end-start-len(prefix) != 64 || (start > 0 && isAlphanumeric(s[start-1])) || (end < len(s) && isAlphanumeric(s[end])) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(prefix + RedactSecret(s[start+len(prefix):end]))
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func isAlphanumeric(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// RedactingWriter redacts the private keys (see RedactSecrets) in everything
+// written to it, line by line, so a key split across writes is still redacted.
+// Flush writes the last line that does not end with a newline.
+type RedactingWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+// NewRedactingWriter returns the writer that redacts to w.
+func NewRedactingWriter(w io.Writer) *RedactingWriter {
+	return &RedactingWriter{w: w}
+}
+
+// Write buffers the bytes, and writes each complete line redacted.
+func (rw *RedactingWriter) Write(p []byte) (int, error) {
+	rw.buf = append(rw.buf, p...)
+	idx := bytes.LastIndexByte(rw.buf, '\n')
+	if idx < 0 {
+		return len(p), nil
+	}
+	lines := string(rw.buf[:idx+1])
+	rw.buf = append(rw.buf[:0], rw.buf[idx+1:]...)
+	if _, err := io.WriteString(rw.w, RedactSecrets(lines)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes the buffered partial line redacted.
+func (rw *RedactingWriter) Flush() error {
+	if len(rw.buf) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(rw.w, RedactSecrets(string(rw.buf)))
+	rw.buf = rw.buf[:0]
+	return err
+}
@@ -0,0 +1,34 @@
+package keyinfo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Refresh recomputes every derived field of the key info from its private key
+// with the current avalanchego encoding, keeping "private_key" as is.
+// It returns the refreshed key info and the fields that changed.
+// The other optional fields (e.g., the additional chains) are kept and
+// recomputed, and "private_key_hex" must be the same key if set, since
+// the private key is the only source of truth.
+func Refresh(ki Info, networkID uint32, hrp string) (Info, []FieldMismatch, error) {
+	if ki.NetworkID != 0 && ki.NetworkID != networkID {
+		return Info{}, nil, fmt.Errorf("key file network_id %d does not match the network ID %d", ki.NetworkID, networkID)
+	}
+	if ki.WatchOnly {
+		return Info{}, nil, errors.New("watch-only key info has no private key to refresh from")
+	}
+	pk, err := DecodePrivateKey(ki.PrivateKey)
+	if err != nil {
+		return Info{}, nil, err
+	}
+	if err := checkPrivateKeyHex(ki, pk); err != nil {
+		return Info{}, nil, err
+	}
+	refreshed, err := rederive(ki, pk, networkID, hrp)
+	if err != nil {
+		return Info{}, nil, err
+	}
+	refreshed.PrivateKey = ki.PrivateKey
+	return refreshed, mismatchedFields(ki, refreshed, ""), nil
+}
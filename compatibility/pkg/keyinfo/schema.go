@@ -0,0 +1,218 @@
+package keyinfo
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// Schema is the JSON Schema (draft-07) of the key info file.
+//
+//go:embed keyinfo.schema.json
+var Schema []byte
+
+// schema is the subset of the JSON Schema keywords that the key info schema
+// uses, so the key files are checked without a JSON Schema dependency.
+// The other keywords (e.g., "$id", "description") are ignored.
+type schema struct {
+	Type                 string             `json:"type"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties *schema            `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	Minimum              *json.Number       `json:"minimum"`
+	Maximum              *json.Number       `json:"maximum"`
+	Const                interface{}        `json:"const"`
+	If                   *schema            `json:"if"`
+	Then                 *schema            `json:"then"`
+	Else                 *schema            `json:"else"`
+}
+
+var keyInfoSchema = func() *schema {
+	var s schema
+	if err := json.Unmarshal(Schema, &s); err != nil {
+		panic(fmt.Errorf("invalid embedded key info schema (%v)", err))
+	}
+	return &s
+}()
+
+// SchemaFieldError is a key file field that does not match the schema.
+type SchemaFieldError struct {
+	// Field is the field path (e.g., "private_key", "networks[0].hrp"),
+	// or empty for the whole file.
+	Field   string
+	Message string
+}
+
+// SchemaError is returned when the key file does not match the schema,
+// with every mismatched field.
+type SchemaError struct {
+	Fields []SchemaFieldError
+}
+
+func (e *SchemaError) Error() string {
+	ss := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		if f.Field == "" {
+			ss[i] = f.Message
+			continue
+		}
+		ss[i] = f.Field + ": " + f.Message
+	}
+	return "key file does not match the key info schema: " + strings.Join(ss, ", ")
+}
+
+// ValidateSchema validates the JSON key file (convert YAML with
+// "yaml.YAMLToJSON" first) against the key info schema, before decoding it,
+// so a wrong type or a missing field is reported by its name.
+// It returns the *SchemaError with every mismatched field.
+func ValidateSchema(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("invalid JSON key file (%v)", err)
+	}
+	fields := keyInfoSchema.validate(v, "")
+	if len(fields) > 0 {
+		return &SchemaError{Fields: fields}
+	}
+	return nil
+}
+
+func (s *schema) validate(v interface{}, path string) []SchemaFieldError {
+	if s.Type != "" && !hasType(v, s.Type) {
+		return []SchemaFieldError{{Field: path, Message: fmt.Sprintf("expected %s, got %s", withArticle(s.Type), withArticle(typeName(v)))}}
+	}
+	if s.Const != nil && !sameValue(v, s.Const) {
+		return []SchemaFieldError{{Field: path, Message: fmt.Sprintf("expected %v", s.Const)}}
+	}
+	var fields []SchemaFieldError
+	if n, ok := v.(json.Number); ok {
+		fields = append(fields, checkRange(n, s.Minimum, s.Maximum, path)...)
+	}
+	if obj, ok := v.(map[string]interface{}); ok {
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				fields = append(fields, SchemaFieldError{Field: joinPath(path, name), Message: "missing required field"})
+			}
+		}
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop := s.Properties[name]
+			if prop == nil {
+				prop = s.AdditionalProperties
+			}
+			if prop != nil {
+				fields = append(fields, prop.validate(obj[name], joinPath(path, name))...)
+			}
+		}
+	}
+	if arr, ok := v.([]interface{}); ok && s.Items != nil {
+		for i, elem := range arr {
+			fields = append(fields, s.Items.validate(elem, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	if s.If != nil {
+		branch := s.Else
+		if len(s.If.validate(v, path)) == 0 {
+			branch = s.Then
+		}
+		if branch != nil {
+			fields = append(fields, branch.validate(v, path)...)
+		}
+	}
+	return fields
+}
+
+func hasType(v interface{}, typ string) bool {
+	switch typ {
+	case "integer":
+		n, ok := v.(json.Number)
+		return ok && isInteger(n)
+	case "number":
+		_, ok := v.(json.Number)
+		return ok
+	}
+	return typeName(v) == typ
+}
+
+func isInteger(n json.Number) bool {
+	r, ok := new(big.Rat).SetString(n.String())
+	return ok && r.IsInt()
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func withArticle(typ string) string {
+	switch typ {
+	case "null":
+		return typ
+	case "integer", "array", "object":
+		return "an " + typ
+	}
+	return "a " + typ
+}
+
+func sameValue(v interface{}, c interface{}) bool {
+	if n, ok := v.(json.Number); ok {
+		v = n.String()
+		c = fmt.Sprint(c)
+	}
+	return v == c
+}
+
+func checkRange(n json.Number, min *json.Number, max *json.Number, path string) []SchemaFieldError {
+	r, ok := new(big.Rat).SetString(n.String())
+	if !ok {
+		return []SchemaFieldError{{Field: path, Message: fmt.Sprintf("invalid number %s", n)}}
+	}
+	for _, bound := range []struct {
+		limit *json.Number
+		cmp   int
+		desc  string
+	}{
+		{min, -1, "at least"},
+		{max, 1, "at most"},
+	} {
+		if bound.limit == nil {
+			continue
+		}
+		l, ok := new(big.Rat).SetString(bound.limit.String())
+		if ok && r.Cmp(l) == bound.cmp {
+			return []SchemaFieldError{{Field: path, Message: fmt.Sprintf("%s is out of range (must be %s %s)", n, bound.desc, bound.limit)}}
+		}
+	}
+	return nil
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,10 @@
+package keyinfo
+
+import "crypto/subtle"
+
+// SecretEqual compares the secret key material in constant time (for the
+// same length), so the comparison does not leak how many leading bytes match.
+// The public values (e.g., addresses) are compared as usual.
+func SecretEqual(a []byte, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
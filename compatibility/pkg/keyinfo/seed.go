@@ -0,0 +1,34 @@
+package keyinfo
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"golang.org/x/crypto/hkdf"
+)
+
+// seedKDFInfo is the HKDF info to separate the seed-derived keys
+// from any other use of the same seed.
+const seedKDFInfo = "avalanche-ops key-info seed"
+
+// NewPrivateKeyFromSeed deterministically derives the private key from the
+// seed of any length, using HKDF-SHA256. The same seed always derives the
+// same key. Only for reproducible tests, never for production keys.
+func NewPrivateKeyFromSeed(seed []byte) (*crypto.PrivateKeySECP256K1R, error) {
+	if len(seed) == 0 {
+		return nil, errors.New("empty seed")
+	}
+	kdf := hkdf.New(sha256.New, seed, nil, []byte(seedKDFInfo))
+	for {
+		skBytes := make([]byte, crypto.SECP256K1RSKLen)
+		if _, err := io.ReadFull(kdf, skBytes); err != nil {
+			return nil, err
+		}
+		// retry the next output in the (~2^-128) case the scalar is out of range
+		if validScalar(skBytes) {
+			return toPrivateKey(skBytes)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package keyinfo
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+)
+
+// signedMessagePrefix is prepended to the message before hashing,
+// so that a signed message cannot be used as a signed transaction.
+// Same convention as the Avalanche Wallet "Sign Message".
+const signedMessagePrefix = "\x1AAvalanche Signed Message:\n"
+
+// MessageHash returns the SHA256 hash of the message using the Avalanche
+// signed message convention: prefix || 4-byte big-endian length || message.
+func MessageHash(msg []byte) []byte {
+	b := make([]byte, 0, len(signedMessagePrefix)+4+len(msg))
+	b = append(b, signedMessagePrefix...)
+	b = append(b, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(b[len(signedMessagePrefix):], uint32(len(msg)))
+	b = append(b, msg...)
+	return hashing.ComputeHash256(b)
+}
+
+// SignMessage signs the message hash, returning the 65-byte [r || s || v] signature.
+func SignMessage(pk *crypto.PrivateKeySECP256K1R, msg []byte) ([]byte, error) {
+	return pk.SignHash(MessageHash(msg))
+}
+
+// RecoverMessageSigner recovers the public key hash (short ID) of the
+// signer of the message.
+func RecoverMessageSigner(msg []byte, sig []byte) (ids.ShortID, error) {
+	pub, err := keyFactory.RecoverHashPublicKey(MessageHash(msg), sig)
+	if err != nil {
+		return ids.ShortID{}, err
+	}
+	return pub.Address(), nil
+}
+
+// EncodeSignature encodes the signature in CB58 with checksum.
+func EncodeSignature(sig []byte) (string, error) {
+	return formatting.EncodeWithChecksum(formatting.CB58, sig)
+}
+
+// DecodeSignature decodes the CB58 signature.
+func DecodeSignature(s string) ([]byte, error) {
+	if max := maxCB58Len(crypto.SECP256K1RSigLen); len(s) > max {
+		return nil, fmt.Errorf("invalid signature length %d characters (expected at most %d characters)", len(s), max)
+	}
+	sig, err := formatting.Decode(formatting.CB58, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != crypto.SECP256K1RSigLen {
+		return nil, fmt.Errorf("invalid signature length %d bytes (expected %d bytes)", len(sig), crypto.SECP256K1RSigLen)
+	}
+	return sig, nil
+}
+
+// ParseShortID parses the chain address (e.g., "X-avax1...")
+// or the CB58 short address into the public key hash.
+func ParseShortID(addr string) (ids.ShortID, error) {
+	if _, _, b, err := formatting.ParseAddress(addr); err == nil {
+		return ids.ToShortID(b)
+	}
+	id, err := ids.ShortFromString(addr)
+	if err != nil {
+		return ids.ShortID{}, fmt.Errorf("%q is neither a chain address nor a short address", addr)
+	}
+	return id, nil
+}
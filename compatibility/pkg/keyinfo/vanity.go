@@ -0,0 +1,100 @@
+package keyinfo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+)
+
+// ErrVanityNotFound is returned when no key matched the prefix
+// within the maximum number of attempts.
+var ErrVanityNotFound = errors.New("no matching key found within the max attempts")
+
+// bech32Charset is the set of characters in the bech32 data part.
+// ref. https://github.com/bitcoin/bips/blob/master/bip-0173.mediawiki
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// ValidateVanityPrefix checks that the prefix can appear
+// in the data part of a bech32 address.
+func ValidateVanityPrefix(prefix string) error {
+	if prefix == "" {
+		return errors.New("empty vanity prefix")
+	}
+	for _, c := range prefix {
+		if !strings.ContainsRune(bech32Charset, c) {
+			return fmt.Errorf("invalid vanity prefix character %q (must be one of %q)", c, bech32Charset)
+		}
+	}
+	return nil
+}
+
+// FindVanityKey generates random keys with the given number of workers
+// until the data part of the X-chain address (after "X-[HRP]1") starts
+// with the prefix, and returns the key with the number of attempts.
+// Each bech32 character encodes 5 bits, so each additional prefix
+// character makes the search ~32 times slower.
+func FindVanityKey(networkID uint32, prefix string, workers int, maxAttempts uint64) (*crypto.PrivateKeySECP256K1R, uint64, error) {
+	if err := ValidateVanityPrefix(prefix); err != nil {
+		return nil, 0, err
+	}
+	addrPfx := "X-" + constants.GetHRP(networkID) + "1" + prefix
+
+	var (
+		attempts uint64
+		once     sync.Once
+		done     = make(chan struct{})
+		wg       sync.WaitGroup
+
+		found    *crypto.PrivateKeySECP256K1R
+		foundAt  uint64
+		foundErr error
+	)
+	finish := func(pk *crypto.PrivateKeySECP256K1R, n uint64, err error) {
+		once.Do(func() {
+			found, foundAt, foundErr = pk, n, err
+			close(done)
+		})
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				n := atomic.AddUint64(&attempts, 1)
+				if n > maxAttempts {
+					return
+				}
+				pk, err := NewPrivateKey()
+				if err != nil {
+					finish(nil, n, err)
+					return
+				}
+				addr, err := EncodeAddr(pk, "X", constants.GetHRP(networkID))
+				if err != nil {
+					finish(nil, n, err)
+					return
+				}
+				if strings.HasPrefix(addr, addrPfx) {
+					finish(pk, n, nil)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if found == nil && foundErr == nil {
+		return nil, maxAttempts, ErrVanityNotFound
+	}
+	return found, foundAt, foundErr
+}
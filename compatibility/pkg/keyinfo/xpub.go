@@ -0,0 +1,124 @@
+package keyinfo
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	eth_crypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// xpubDepth is the depth of the account extended public key
+// at "m/44'/9000'/0'", as exported by the Ledger Avalanche app.
+const xpubDepth = 3
+
+// AccountDerivationPath returns the path of the account extended public key,
+// the hardened prefix of DerivationPath.
+func AccountDerivationPath() string {
+	return fmt.Sprintf("m/44'/%d'/0'", AvaxCoinType)
+}
+
+// DecodePublicKeyFromXpub derives the 33-byte compressed public key at
+// "0/[accountIndex]" from the account extended public key at "m/44'/9000'/0'",
+// which is the same key as DerivationPath(accountIndex) derives from the mnemonic.
+func DecodePublicKeyFromXpub(xpub string, accountIndex uint32) ([]byte, error) {
+	key, err := hdkeychain.NewKeyFromString(xpub)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extended public key (%w)", err)
+	}
+	if key.IsPrivate() {
+		return nil, errors.New("expected an extended public key (xpub), got an extended private key")
+	}
+	if key.Depth() != xpubDepth {
+		return nil, fmt.Errorf("extended public key depth %d != %d (expected the account key at %q)", key.Depth(), xpubDepth, AccountDerivationPath())
+	}
+	for _, idx := range []uint32{0, accountIndex} {
+		key, err = key.Child(idx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	pub, err := key.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+	return pub.SerializeCompressed(), nil
+}
+
+// NewWatchOnlyInfo derives the key info from the compressed public key,
+// with the private key fields left empty.
+func NewWatchOnlyInfo(pubBytes []byte, networkID uint32, hrp string) (Info, error) {
+	pub, err := keyFactory.ToPublicKey(pubBytes)
+	if err != nil {
+		return Info{}, err
+	}
+	ecdsaPub, err := eth_crypto.DecompressPubkey(pubBytes)
+	if err != nil {
+		return Info{}, err
+	}
+	pubHash := pub.Address().Bytes()
+
+	xAddr, err := FormatAddress("X", hrp, pubHash)
+	if err != nil {
+		return Info{}, err
+	}
+	pAddr, err := FormatAddress("P", hrp, pubHash)
+	if err != nil {
+		return Info{}, err
+	}
+	cAddr, err := FormatAddress("C", hrp, pubHash)
+	if err != nil {
+		return Info{}, err
+	}
+	shortAddr, err := formatting.EncodeWithChecksum(formatting.CB58, pubHash)
+	if err != nil {
+		return Info{}, err
+	}
+
+	return Info{
+		XAddress:              xAddr,
+		PAddress:              pAddr,
+		CAddress:              cAddr,
+		ShortAddress:          shortAddr,
+		EthAddress:            eth_crypto.PubkeyToAddress(*ecdsaPub).String(),
+		PublicKeyCompressed:   hex.EncodeToString(pubBytes),
+		PublicKeyUncompressed: hex.EncodeToString(eth_crypto.FromECDSAPub(ecdsaPub)),
+		WatchOnly:             true,
+	}, nil
+}
+
+// validateWatchOnly checks that the addresses of the watch-only key info
+// are derived from its compressed public key.
+func validateWatchOnly(ki Info, networkID uint32, hrp string, only map[string]bool) error {
+	if ki.PrivateKey != "" || ki.PrivateKeyHex != "" {
+		return errors.New("watch-only key info must not have the private key")
+	}
+	if ki.Addresses != nil || ki.Networks != nil {
+		return errors.New("watch-only key info does not support additional chains or networks")
+	}
+	pubBytes, err := hex.DecodeString(ki.PublicKeyCompressed)
+	if err != nil {
+		return fmt.Errorf("invalid public_key_compressed %q (%w)", ki.PublicKeyCompressed, err)
+	}
+	derived, err := NewWatchOnlyInfo(pubBytes, networkID, hrp)
+	if err != nil {
+		return err
+	}
+	if selected(only, "c_address") && selected(only, "eth_address") {
+		if err := checkCChainAccount(ki, derived); err != nil {
+			return err
+		}
+	}
+	if selected(only, "eth_address") {
+		if err := checkEthChecksum(ki.EthAddress, derived.EthAddress); err != nil {
+			return err
+		}
+	}
+	derived.NetworkID, derived.EthAccounts, derived.HDAccounts = ki.NetworkID, ki.EthAccounts, ki.HDAccounts
+	if fields := selectedFields(mismatchedFields(ki, derived, ""), only); len(fields) > 0 {
+		return &MismatchError{Fields: fields}
+	}
+	return nil
+}
@@ -19,10 +19,532 @@ pushd ./compatibility
 go run ./key-info-validate/main.go /tmp/test.key.json 9999
 popd
 
+###
+pushd ./compatibility
+# the table-driven tests of the known keys (pkg/keyinfo/testdata)
+go test ./...
+go run ./key-info-validate/main.go generate 9999 /tmp/test.key.json --force
+go run ./key-info-validate/main.go /tmp/test.key.json 9999
+rm -rf /tmp/test-keys && mkdir -p /tmp/test-keys
+go run ./key-info-validate/main.go generate 9999 /tmp/test-keys/1.key.json
+go run ./key-info-validate/main.go generate 9999 /tmp/test-keys/2.key.json
+go run ./key-info-validate/main.go validate-dir /tmp/test-keys 9999
+test "$(go run ./key-info-validate/main.go validate-dir /tmp/test-keys 9999 --format csv --no-header | wc -l)" -eq 2
+# a handful of key files in one run, with a result for each and the summary
+go run ./key-info-validate/main.go validate /tmp/test-keys/1.key.json /tmp/test-keys/2.key.json ../artifacts/ewoq.key.json 9999 | grep "3 succeeded, 0 failed"
+go run ./key-info-validate/main.go /tmp/test-keys/1.key.json ../artifacts/ewoq.legacy.key.json 9999 --format csv --no-header | grep -c ",9999,X-custom1" | grep -x 2
+go run ./key-info-validate/main.go ewoq 12345 > /tmp/ewoq.local.key.yaml
+go run ./key-info-validate/main.go /tmp/ewoq.local.key.yaml /tmp/ewoq.local.key.yaml --network local | grep "2 succeeded, 0 failed"
+go run ./key-info-validate/main.go /tmp/test-keys/1.key.json ../artifacts/ewoq.key.json 1 2>&1 | grep -E "^../artifacts/ewoq.key.json +X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p +FAIL"
+go run ./key-info-validate/main.go /tmp/test-keys/1.key.json ../artifacts/ewoq.key.json 1 2>&1 | grep -F "exit status 1"
+go run ./key-info-validate/main.go /tmp/test-keys/1.key.json ../artifacts/ewoq.key.json 9999 --migrate 2>&1 | grep -F -- "--migrate cannot be used with multiple key files"
+# the same key in two files fails --check-duplicates with exit code 1
+go run ./key-info-validate/main.go validate-dir /tmp/test-keys 9999 --check-duplicates
+rm -rf /tmp/test-keys-dup && mkdir -p /tmp/test-keys-dup
+cp /tmp/test-keys/1.key.json /tmp/test-keys-dup/1.key.json
+cp ../artifacts/ewoq.key.json /tmp/test-keys-dup/a.key.json
+cp ../artifacts/ewoq.key.json /tmp/test-keys-dup/b.key.json
+go run ./key-info-validate/main.go validate-dir /tmp/test-keys-dup 9999 --check-duplicates 2>&1 | grep -F "DUPLICATE /tmp/test-keys-dup/a.key.json and /tmp/test-keys-dup/b.key.json (same private key)"
+go run ./key-info-validate/main.go validate-dir /tmp/test-keys-dup 9999 --check-duplicates 2>&1 | grep -F "exit status 1"
+go run ./key-info-validate/main.go validate-dir /tmp/test-keys-dup 9999
+# match-addresses finds the owner key of each address on any chain and HRP
+rm -rf /tmp/test-keys-match && mkdir -p /tmp/test-keys-match
+cp ../artifacts/ewoq.key.json /tmp/test-keys-match/ewoq.key.json
+cp /tmp/test-keys/1.key.json /tmp/test-keys-match/1.key.json
+cat > /tmp/addresses.txt <<EOF
+# the ewoq key on the local network, and on mainnet
+X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p
+P-avax18jma8ppw3nhx5r4ap8clazz0dps7rv5ukulre5
+0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC
+$(grep short_address /tmp/test-keys/1.key.json | cut -d '"' -f 4)
+EOF
+go run ./key-info-validate/main.go match-addresses /tmp/test-keys-match /tmp/addresses.txt | grep "4 matched, 0 unmatched"
+go run ./key-info-validate/main.go match-addresses /tmp/test-keys-match /tmp/addresses.txt --format csv --no-header | grep -c "/tmp/test-keys-match/ewoq.key.json" | grep -x 3
+# an address of no key in the directory fails with exit code 1
+echo "0x613040a239BDfCF110969fecB41c6f92EA3515C0" >> /tmp/addresses.txt
+go run ./key-info-validate/main.go match-addresses /tmp/test-keys-match /tmp/addresses.txt 2>&1 | grep -E "^0x613040a239BDfCF110969fecB41c6f92EA3515C0 +unknown|1 of 5 addresses do not belong to any key|exit status 1" | wc -l | grep -x 3
+rm -rf /tmp/test-keys-batch
+go run ./key-info-validate/main.go generate 9999 --count 20 --out-dir /tmp/test-keys-batch
+go run ./key-info-validate/main.go /tmp/test-keys-batch/key-19.json 9999 --quiet
+# fails on the non-empty output directory without "--force"
+go run ./key-info-validate/main.go generate 9999 --count 20 --out-dir /tmp/test-keys-batch 2>&1 | grep "is not empty"
+# concurrent key decoding with the shared key factory
+go run -race ./key-info-validate/main.go generate 9999 --count 200 --out-dir /tmp/test-keys-race --workers 16 --force
+go run -race ./key-info-validate/main.go validate-dir /tmp/test-keys 9999 --workers 16
+# the progress is only reported when stderr is a terminal, and never with --quiet
+test "$(go run ./key-info-validate/main.go validate-dir /tmp/test-keys 9999 2>&1 >/dev/null | grep -c "validated 2/2 files")" -eq 0
+test "$(go run ./key-info-validate/main.go validate-dir /tmp/test-keys 9999 --quiet 2>&1 >/dev/null | wc -c)" -eq 0
+if command -v script >/dev/null; then
+  script -qc "go run ./key-info-validate/main.go validate-dir /tmp/test-keys 9999 >/dev/null" /dev/null | grep "validated 2/2 files (100%), 0 failed"
+fi
+# --timeout stops starting new work, and reports the partial results with exit code 4
+rm -rf /tmp/test-keys-timeout
+go run ./key-info-validate/main.go generate 9999 --count 100000 --out-dir /tmp/test-keys-timeout --timeout 10ms 2>&1 | grep -E "timed out \(context deadline exceeded\), generated [0-9]+ of 100000 keys|exit status 4" | wc -l | grep -x 2
+rm -rf /tmp/test-keys-timeout
+go run ./key-info-validate/main.go generate 9999 --count 50 --out-dir /tmp/test-keys-timeout
+for f in /tmp/test-keys-timeout/key-*.json; do mv "${f}" "${f%.json}.key.json"; done
+go run ./key-info-validate/main.go validate-dir /tmp/test-keys-timeout 9999 --timeout 1m
+# the report is in the lexical order of the paths, for any number of workers
+go run ./key-info-validate/main.go validate-dir /tmp/test-keys-timeout 9999 --workers 1 > /tmp/test-keys-timeout.1.txt
+go run ./key-info-validate/main.go validate-dir /tmp/test-keys-timeout 9999 --workers 16 | diff - /tmp/test-keys-timeout.1.txt
+go run ./key-info-validate/main.go validate-dir /tmp/test-keys-timeout 9999 --workers 16 --format csv --no-header | cut -d, -f1 | diff - <(printf '%s\n' /tmp/test-keys-timeout/*.key.json | LC_ALL=C sort)
+go run ./key-info-validate/main.go validate-dir /tmp/test-keys-timeout 9999 --timeout 1ns 2>&1 | grep -E "timed out \(context deadline exceeded\), validated [0-9]+ of 50 files|exit status 4" | wc -l | grep -x 2
+# same seed always derives the same key
+go run ./key-info-validate/main.go generate 9999 /tmp/seed.key.json --seed 0x74657374 --force | grep X-custom1rr0kky7uqt8nxuwls0l4qm860m9nwmmdzdmudg
+# byte-identical key files for the same key, regardless of the flag order
+go run ./key-info-validate/main.go generate 9999 /tmp/stable.1.key.json --seed 0x74657374 --force --chains mychain,X,C --networks 12345,1,5
+go run ./key-info-validate/main.go generate 9999 /tmp/stable.2.key.json --seed 0x74657374 --force --chains C,mychain,X --networks 5,12345,1
+cmp /tmp/stable.1.key.json /tmp/stable.2.key.json
+go run ./key-info-validate/main.go generate 9999 /tmp/stable.1.key.yaml --seed 0x74657374 --force --chains mychain,X,C --networks 12345,1,5
+go run ./key-info-validate/main.go generate 9999 /tmp/stable.2.key.yaml --seed 0x74657374 --force --chains C,mychain,X --networks 5,12345,1
+cmp /tmp/stable.1.key.yaml /tmp/stable.2.key.yaml
+go run ./key-info-validate/main.go /tmp/seed.key.json 9999
+go run ./key-info-validate/main.go vanity 9999 qq /tmp/vanity.key.json --force
+go run ./key-info-validate/main.go /tmp/vanity.key.json 9999 --quiet
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format json
+# single-line JSON with the same contents as the indented JSON
+test "$(go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format json --json-compact | wc -l)" -eq 1
+test "$(go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format json --json-compact)" = "$(go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format json | tr -d ' \n')"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format csv | grep -F "9999,X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p,P-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p,C-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p,0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC,6Y3kysjF9jnHnYkdS9yGAuoHyae2eNmeV"
+# same addresses without the chain alias, and as the public key hash
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format csv --address-style bech32-only | grep -F "9999,custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p,custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p,custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p,0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC"
+# shell exports to "eval", without the private keys unless --show-secret
+eval "$(go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format env --env-prefix EWOQ_)"
+test "${EWOQ_X_ADDRESS}" = "X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p"
+test "${EWOQ_ETH_ADDRESS}" = "0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC"
+test "$(go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format env | grep -c PRIVATE_KEY)" -eq 0
+eval "$(go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format env --show-secret)"
+test "${PRIVATE_KEY}" = "PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format env --env-prefix 1BAD 2>&1 | grep -F "invalid --env-prefix"
+# the built-in address encoders encode the same addresses as the key info
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --encoder x,P,eth | grep -x "encoder x: X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --encoder x,P,eth | grep -x "encoder p: P-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --encoder x,P,eth | grep -x "encoder eth: 0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 1 --hrp custom --encoder c --format json | grep -F '"c": "C-avax18jma8ppw3nhx5r4ap8clazz0dps7rv5ukulre5"'
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --encoder mychain 2>&1 | grep -F 'unknown address encoder "mychain" (expected one of c, eth, p, x)'
+# the labels are printed next to the labeled addresses only
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --labels "p=validator reward,ETH=faucet funding" > /tmp/ewoq.labels.txt
+grep -x "p_address: P-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p (validator reward)" /tmp/ewoq.labels.txt
+grep -x "eth_address: 0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC (faucet funding)" /tmp/ewoq.labels.txt
+test "$(grep -c "^x_address: .*(" /tmp/ewoq.labels.txt)" -eq 0
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --labels "x=validator reward" --format json --json-compact | grep -F '"labels":[{"field":"x_address","address":"X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p","label":"validator reward"}]'
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --labels "z=faucet" 2>&1 | grep -F 'unknown --labels chain "z" (expected x, p, c, eth, or short)'
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --labels "x=a,x=b" 2>&1 | grep -F "exit status 2"
+# --only prints nothing but the address of the chain
+test "$(go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --only x 2>&1)" = "X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p"
+test "$(cat ../artifacts/ewoq.key.json | go run ./key-info-validate/main.go - 9999 --only ETH 2>&1)" = "0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC"
+test "$(go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --only short 2>&1)" = "6Y3kysjF9jnHnYkdS9yGAuoHyae2eNmeV"
+test "$(go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --only p --address-style bech32-only 2>&1)" = "custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --only z 2>&1 | grep -F 'unknown --only chain "z" (expected x, p, c, eth, or short)'
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 1 --only x 2>&1 | grep -F "exit status 5"
+# the Terraform variable map of the addresses, for the .tfvars files
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format hcl --hcl-variable ewoq_key > /tmp/ewoq.auto.tfvars
+test "$(head -1 /tmp/ewoq.auto.tfvars)" = "ewoq_key = {"
+grep -E '^  x_address += "X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p"$' /tmp/ewoq.auto.tfvars
+grep -E '^  network_id += 9999$' /tmp/ewoq.auto.tfvars
+test "$(tail -1 /tmp/ewoq.auto.tfvars)" = "}"
+test "$(grep -c private_key /tmp/ewoq.auto.tfvars)" -eq 0
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format hcl --show-secret | grep -E '^  private_key += "PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN"$'
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --hcl-variable ewoq_key 2>&1 | grep -F -- "--hcl-variable requires --format hcl"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format json --address-style hash-hex | grep -F '"x_address": "0x3cb7d3842e8cee6a0ebd09f1fe884f6861e1b29c"'
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --address-style hash-hex | grep -F "p_address: 0x3cb7d3842e8cee6a0ebd09f1fe884f6861e1b29c"
+# same as "avalanchego/genesis/genesis_local.go"
+go run ./key-info-validate/main.go ewoq 12345 | grep "x_address: X-local18jma8ppw3nhx5r4ap8clazz0dps7rv5u00z96u"
+go run ./key-info-validate/main.go ewoq --network local | grep "x_address: X-local18jma8ppw3nhx5r4ap8clazz0dps7rv5u00z96u"
+go run ./key-info-validate/main.go generate /tmp/test.fuji.key.json --network fuji --force
+go run ./key-info-validate/main.go /tmp/test.fuji.key.json 5 --network fuji
+go run ./key-info-validate/main.go /tmp/test.fuji.key.json 1 --network fuji 2>&1 | grep "conflicts with the network ID 1"
+# same C-chain balance as "avalanchego/genesis/genesis_local.go"
+go run ./key-info-validate/main.go ewoq 12345 > /tmp/ewoq.local.key.yaml
+go run ./key-info-validate/main.go /tmp/ewoq.local.key.yaml 12345 --genesis-alloc --balance 50000000000000000 | grep -i '"balance": "0x295BE96E64066972000000"'
+go run ./key-info-validate/main.go ewoq 9999 --include-pubkey | grep -E "^public_key_compressed: 0[23][0-9a-f]{64}$"
+go run ./key-info-validate/main.go generate 9999 /tmp/test.pubkey.key.json --include-pubkey --force
+go run ./key-info-validate/main.go /tmp/test.pubkey.key.json 9999 --format json | grep -E '"public_key_compressed": "0[23]'
+# fails if the X/P/C addresses do not encode the same public key hash
+go run ./key-info-validate/main.go explain ../artifacts/ewoq.key.json 9999 | grep 0x3cb7d3842e8cee6a0ebd09f1fe884f6861e1b29c
+# the X-chain address has no asset ID, so it is the same for AVAX and any
+# other asset, by the alias or the X-chain ID (e.g., "2oYMBNV4..." of mainnet)
+go run ./key-info-validate/main.go explain ../artifacts/ewoq.key.json 9999 | grep -F "same for AVAX and every other X-chain asset"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --chains X | grep -x "X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --chains X,2oYMBNV4eNHyqk2fjjV5nVQLDbtmNJzq5s3qs3Lo6ftnC6FByM | grep -x "X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --chains X,2oYMBNV4eNHyqk2fjjV5nVQLDbtmNJzq5s3qs3Lo6ftnC6FByM | grep -x "2oYMBNV4eNHyqk2fjjV5nVQLDbtmNJzq5s3qs3Lo6ftnC6FByM-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p"
+go run ./key-info-validate/main.go explain-c ../artifacts/ewoq.key.json 9999 | grep "SUCCESS: c_address and eth_address derive from the same public key"
+# eth address of another key (PrivateKey-2kqWNDaqUKQyE4ZsV5GLCGeizE6sHAJVyjnfjXoXrtcZpK9M67) spliced in
+sed 's/0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC/0x613040a239BDfCF110969fecB41c6f92EA3515C0/' ../artifacts/ewoq.key.json > /tmp/ewoq.spliced.key.json
+go run ./key-info-validate/main.go explain-c /tmp/ewoq.spliced.key.json 9999 2>&1 | grep "eth_address is not the same C-chain account as c_address"
+go run ./key-info-validate/main.go /tmp/ewoq.spliced.key.json 9999 2>&1 | grep "eth_address is not the same C-chain account as c_address"
+# the reward address of the same key is its P-chain address
+test "$(go run ./key-info-validate/main.go reward-address ../artifacts/ewoq.key.json 9999 | sed -n 's/^reward address: //p')" = "$(grep p_address ../artifacts/ewoq.key.json | cut -d'"' -f4)"
+go run ./key-info-validate/main.go generate 9999 /tmp/reward.key.json --force
+test "$(go run ./key-info-validate/main.go reward-address ../artifacts/ewoq.key.json 9999 --reward-key /tmp/reward.key.json | sed -n 's/^reward address: //p')" = "$(grep p_address /tmp/reward.key.json | cut -d'"' -f4)"
+go run ./key-info-validate/main.go reward-address ../artifacts/ewoq.key.json 9999 --reward-key ../artifacts/ewoq.key.json 2>&1 | grep "the rewards are not separated"
+# funding instructions with the faucet of the network
+go run ./key-info-validate/main.go generate 5 /tmp/fuji.key.json --force --funding-help | grep "open the Avalanche Fuji faucet: https://faucet.avax.network/"
+go run ./key-info-validate/main.go /tmp/fuji.key.json 5 --funding-help | grep -A1 "paste the eth address" | grep "$(grep eth_address /tmp/fuji.key.json | cut -d'"' -f4)"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --funding-help | grep "no public faucet for this network"
+go run ./key-info-validate/main.go /tmp/fuji.key.json 5 --funding-help --format hcl 2>&1 | grep -F -- "--funding-help cannot be used with --format hcl"
+go run ./key-info-validate/main.go /tmp/fuji.key.json 5 --funding-help --genesis-alloc 2>&1 | grep -F -- "--funding-help cannot be used with --genesis-alloc"
+go run ./key-info-validate/main.go identify X-avax18jma8ppw3nhx5r4ap8clazz0dps7rv5ukulre5 | grep "public key hash: 0x3cb7d3842e8cee6a0ebd09f1fe884f6861e1b29c"
+go run ./key-info-validate/main.go identify 6Y3kysjF9jnHnYkdS9yGAuoHyae2eNmeV | grep "public key hash: 0x3cb7d3842e8cee6a0ebd09f1fe884f6861e1b29c"
+go run ./key-info-validate/main.go identify 0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC | grep "type: eth address"
+go run ./key-info-validate/main.go identify not-an-address 2>&1 | grep "unrecognized address format"
+# same public key hash with the bech32m (BIP350) checksum
+go run ./key-info-validate/main.go identify X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5us6a4mr | grep "type: bech32m chain address"
+go run ./key-info-validate/main.go identify X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5us6a4mr | grep "public key hash: 0x3cb7d3842e8cee6a0ebd09f1fe884f6861e1b29c"
+# the UTXO owner hash and ID of the ewoq key, the same for the key file and any of its addresses
+printf 'owner hash: 0x3cb7d3842e8cee6a0ebd09f1fe884f6861e1b29c\nowner ID: 6Y3kysjF9jnHnYkdS9yGAuoHyae2eNmeV\n' > /tmp/ewoq.owner-id.txt
+for owner in ../artifacts/ewoq.key.json X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p P-avax18jma8ppw3nhx5r4ap8clazz0dps7rv5ukulre5 6Y3kysjF9jnHnYkdS9yGAuoHyae2eNmeV; do
+  go run ./key-info-validate/main.go owner-id ${owner} | diff - /tmp/ewoq.owner-id.txt
+done
+go run ./key-info-validate/main.go owner-id 0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC 2>&1 | grep -F "is a C-chain EVM account, which owns no UTXOs"
+go run ./key-info-validate/main.go verify-address ../artifacts/ewoq.key.json X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5us6a4mr 2>&1 | grep "bech32m address where bech32 is expected"
+go run ./key-info-validate/main.go generate 9999 /tmp/test.hrp.key.json --hrp mynet --force
+go run ./key-info-validate/main.go /tmp/test.hrp.key.json 9999 --hrp mynet
+go run ./key-info-validate/main.go ewoq 9999 --networks 1,5,12345 --chains X,P,C,mychain > /tmp/ewoq.networks.key.yaml
+go run ./key-info-validate/main.go /tmp/ewoq.networks.key.yaml 9999
+# --migrate keeps the public keys, and the additional chains and networks
+go run ./key-info-validate/main.go ewoq 9999 --networks 1,5 --chains X,P,C,mychain --include-pubkey > /tmp/ewoq.full.key.yaml
+test "$(grep -c "public_key_\|mychain\|network_id" /tmp/ewoq.full.key.yaml)" -eq 7
+cp /tmp/ewoq.full.key.yaml /tmp/ewoq.full.migrate.key.yaml
+go run ./key-info-validate/main.go /tmp/ewoq.full.migrate.key.yaml 9999 --migrate
+diff /tmp/ewoq.full.key.yaml /tmp/ewoq.full.migrate.key.yaml
+# --out for another network keeps them too, with the addresses of that network
+go run ./key-info-validate/main.go /tmp/ewoq.full.key.yaml 1 --out /tmp/ewoq.full.1.key.yaml --force
+test "$(grep -c "public_key_\|mychain\|network_id" /tmp/ewoq.full.1.key.yaml)" -eq 7
+diff <(grep public_key_ /tmp/ewoq.full.key.yaml) <(grep public_key_ /tmp/ewoq.full.1.key.yaml)
+grep -x "  mychain: mychain-avax18jma8ppw3nhx5r4ap8clazz0dps7rv5ukulre5" /tmp/ewoq.full.1.key.yaml
+go run ./key-info-validate/main.go /tmp/ewoq.full.1.key.yaml 1 --strict
+# --networks adds the network to the stored ones
+go run ./key-info-validate/main.go /tmp/ewoq.full.key.yaml 9999 --out /tmp/ewoq.full.12345.key.yaml --networks 12345 --force
+test "$(grep -c "network_id: " /tmp/ewoq.full.12345.key.yaml)" -eq 3
+# the P-chain ID instead of the "P" alias
+go run ./key-info-validate/main.go ewoq 9999 --chains 11111111111111111111111111111111LpoYY | grep "11111111111111111111111111111111LpoYY-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p"
+go run ./key-info-validate/main.go ewoq 9999 --chains 11111111111111111111111111111111LpoYZ 2>&1 | grep "must be the CB58 encoding of 32 bytes"
+# regenerate the key file without "eth_address"
+grep -v eth_address ../artifacts/ewoq.key.json | sed 's/"short_address": "\(.*\)",/"short_address": "\1"/' > /tmp/ewoq.old.key.json
+go run ./key-info-validate/main.go /tmp/ewoq.old.key.json 9999 --out /tmp/ewoq.new.key.json --force
+diff /tmp/ewoq.new.key.json ../artifacts/ewoq.key.json
+# old key file without "private_key_hex", "short_address", and "eth_address"
+go run ./key-info-validate/main.go ../artifacts/ewoq.legacy.key.json 9999 | grep "populated missing fields: private_key_hex, short_address, eth_address"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --strict
+go run ./key-info-validate/main.go ../artifacts/ewoq.legacy.key.json 9999 --strict 2>&1 | grep -F 'private_key_hex (stored "", derived "5628...8027")'
+# lowercased eth address without the EIP-55 checksum casing
+sed 's/0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC/0x8db97c7cece249c2b98bdc0226cc4c2a57bf52fc/' ../artifacts/ewoq.key.json > /tmp/ewoq.lowercase.key.json
+go run ./key-info-validate/main.go /tmp/ewoq.lowercase.key.json 9999 2>&1 | grep -F 'does not have the EIP-55 checksum casing (expected "0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")'
+go run ./key-info-validate/main.go /tmp/ewoq.lowercase.key.json 9999 --strict 2>&1 | grep -F 'does not have the EIP-55 checksum casing'
+# --fields compares only the named fields, strictly, and ignores the others
+go run ./key-info-validate/main.go /tmp/ewoq.lowercase.key.json 9999 --fields x,p,c,short,hex
+go run ./key-info-validate/main.go /tmp/ewoq.lowercase.key.json 9999 --fields eth 2>&1 | grep -F 'does not have the EIP-55 checksum casing'
+go run ./key-info-validate/main.go ../artifacts/ewoq.legacy.key.json 9999 --fields X,P,C --quiet | grep -x SUCCESS
+go run ./key-info-validate/main.go ../artifacts/ewoq.legacy.key.json 9999 --fields x,eth 2>&1 | grep -F 'eth_address (stored "", derived "0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")'
+sed 's/"x_address": "X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p"/"x_address": "X-custom1wrong"/' ../artifacts/ewoq.key.json > /tmp/ewoq.wrong-x.key.json
+go run ./key-info-validate/main.go /tmp/ewoq.wrong-x.key.json 9999 --fields eth
+go run ./key-info-validate/main.go /tmp/ewoq.wrong-x.key.json 9999 --fields x 2>&1 | grep -F 'x_address (stored "X-custom1wrong", derived "X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p")'
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --fields x,z 2>&1 | grep -F 'unknown field "z" (expected one of hex, x, p, c, short, eth, pubkey, chains, networks, eth-accounts, hd-accounts)'
+# --no-checksum prints and accepts the CB58 encodings without the checksum, marked non-standard
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --no-checksum --show-secret | grep -x "private_key (NON-STANDARD, no checksum): PrivateKey-6oKzCLfg5MmczcEeQ2CxRuo4TwqeFcCPMUjizbvFYTp2"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --no-checksum | grep -x "private_key (NON-STANDARD, no checksum): PrivateKey-6oKz...YTp2"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --no-checksum | grep -x "short_address (NON-STANDARD, no checksum): r4dB8GnEhG5dw7mxVoRgQ49RRNT"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --no-checksum 2>&1 | grep -F "NON-STANDARD encoding without the CB58 checksum"
+sed 's/PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN/PrivateKey-6oKzCLfg5MmczcEeQ2CxRuo4TwqeFcCPMUjizbvFYTp2/; s/6Y3kysjF9jnHnYkdS9yGAuoHyae2eNmeV/r4dB8GnEhG5dw7mxVoRgQ49RRNT/' ../artifacts/ewoq.key.json > /tmp/ewoq.no-checksum.key.json
+go run ./key-info-validate/main.go /tmp/ewoq.no-checksum.key.json 9999 --no-checksum --quiet | grep -x SUCCESS
+go run ./key-info-validate/main.go /tmp/ewoq.no-checksum.key.json 9999 2>&1 | grep -F "CB58 checksum failed"
+go run ./key-info-validate/main.go /tmp/ewoq.no-checksum.key.json 9999 --no-checksum --format json 2>&1 | grep -F -- "--no-checksum cannot be used with --format"
+# a flag the --format does not print is rejected, instead of being ignored
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format csv --include-pubkey 2>&1 | grep -F -- "--include-pubkey cannot be used with --format csv"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --genesis-alloc --address-style hash-hex 2>&1 | grep -F -- "--address-style cannot be used with --genesis-alloc"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --balance 1 2>&1 | grep -F -- "--balance requires --genesis-alloc"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --json-compact 2>&1 | grep -F -- "--json-compact requires --format json or --genesis-alloc"
+cp ../artifacts/ewoq.legacy.key.json /tmp/ewoq.legacy.key.json
+go run ./key-info-validate/main.go /tmp/ewoq.legacy.key.json 9999 --migrate
+diff /tmp/ewoq.legacy.key.json ../artifacts/ewoq.key.json
+# the same key with the cosmetic differences canonicalizes to the same bytes
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --canonicalize > /tmp/ewoq.canonical.json
+go run ./key-info-validate/main.go ../artifacts/ewoq.legacy.key.json 9999 --canonicalize --out /tmp/ewoq.legacy.canonical.json --force
+cmp /tmp/ewoq.canonical.json /tmp/ewoq.legacy.canonical.json
+go run ./key-info-validate/main.go /tmp/ewoq.lowercase.key.json 9999 --canonicalize | cmp - /tmp/ewoq.canonical.json
+go run ./key-info-validate/main.go ../artifacts/ewoq.subnet-cli.key 9999 --key-format subnet-cli --canonicalize | cmp - /tmp/ewoq.canonical.json
+sed -e 's/X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p/X-CUSTOM18JMA8PPW3NHX5R4AP8CLAZZ0DPS7RV5U9XDE7P/' ../artifacts/ewoq.key.json | go run ./key-info-validate/main.go - 9999 --canonicalize | cmp - /tmp/ewoq.canonical.json
+go run ./key-info-validate/main.go ewoq 9999 > /tmp/ewoq.9999.key.yaml
+go run ./key-info-validate/main.go /tmp/ewoq.9999.key.yaml 9999 --canonicalize | cmp - /tmp/ewoq.canonical.json
+# recompute the derived fields of an outdated key file, keeping the private key
+cp ../artifacts/ewoq.legacy.key.json /tmp/ewoq.refresh.key.json
+go run ./key-info-validate/main.go refresh /tmp/ewoq.refresh.key.json 9999 --dry-run | grep "3 fields changed"
+diff /tmp/ewoq.refresh.key.json ../artifacts/ewoq.legacy.key.json
+go run ./key-info-validate/main.go refresh /tmp/ewoq.refresh.key.json 9999 | grep -E "^eth_address +0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC$"
+diff /tmp/ewoq.refresh.key.json ../artifacts/ewoq.key.json
+go run ./key-info-validate/main.go refresh /tmp/ewoq.refresh.key.json 9999 | grep "is up to date"
+cat ../artifacts/ewoq.key.json | go run ./key-info-validate/main.go - 9999
+# network ID from the "network_id" field in the key file
+sed 's/"private_key": /"network_id": 9999,\n    "private_key": /' ../artifacts/ewoq.key.json > /tmp/ewoq.network-id.key.json
+go run ./key-info-validate/main.go /tmp/ewoq.network-id.key.json
+go run ./key-info-validate/main.go /tmp/ewoq.network-id.key.json 1 2>&1 | grep "network ID 1 disagrees with network_id 9999"
+test "$(go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --quiet)" = "SUCCESS"
+# revalidate on each change until interrupted
+go build -o /tmp/key-info-validate ./key-info-validate/main.go
+cp ../artifacts/ewoq.key.json /tmp/ewoq.watch.key.json
+/tmp/key-info-validate /tmp/ewoq.watch.key.json 9999 --watch --quiet > /tmp/ewoq.watch.out 2>&1 &
+watch_pid=$!
+sleep 1
+sed -i 's/TXtNN"/TXtNM"/' /tmp/ewoq.watch.key.json
+sleep 1
+cp ../artifacts/ewoq.key.json /tmp/ewoq.watch.key.json
+sleep 1
+kill -INT ${watch_pid}
+wait ${watch_pid}
+test "$(grep -c "^SUCCESS$" /tmp/ewoq.watch.out)" -eq 2
+grep "CB58 checksum failed" /tmp/ewoq.watch.out
+# pinned addresses for CI
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --quiet --assert-x X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p --assert-p P-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p --assert-c C-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p --assert-eth 0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --quiet --assert-x X-avax18jma8ppw3nhx5r4ap8clazz0dps7rv5ukulre5 2>&1 | grep -F -- '--assert-x expected "X-avax18jma8ppw3nhx5r4ap8clazz0dps7rv5ukulre5", actual "X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p"'
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --log-level debug 2>&1 >/dev/null | grep "DEBUG: decoding the keyinfo key"
+test -z "$(go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 1 --hrp custom --log-level error 2>&1 >/dev/null)"
+# non-standard private key prefix, used for both decoding and encoding
+sed 's/"PrivateKey-/"SecretKey-/' ../artifacts/ewoq.key.json > /tmp/fork.key.json
+go run ./key-info-validate/main.go /tmp/fork.key.json 9999 --key-prefix SecretKey-
+go run ./key-info-validate/main.go /tmp/fork.key.json 9999 2>&1 | grep 'private key prefix mismatch (got "SecretKey-", expected "PrivateKey-")'
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --key-prefix SecretKey- 2>&1 | grep 'private key prefix mismatch (got "PrivateKey-", expected "SecretKey-")'
+go run ./key-info-validate/main.go ewoq 9999 --key-prefix SecretKey- | grep "private_key: SecretKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN"
+go run ./key-info-validate/main.go ewoq 9999 --key-prefix SecretKey 2>&1 | grep "invalid private key prefix"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 99999 --strict-network 2>&1 | grep "unrecognized network ID 99999"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 | grep -F "PrivateKey-ewoq...XtNN"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --verbose | grep -E "^public key hash +0x3cb7d3842e8cee6a0ebd09f1fe884f6861e1b29c$"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --verbose --format env 2>&1 | grep -F -- "--verbose cannot be used with --format env"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --qr --qr-chain P --qr-out /tmp/ewoq.qr.png
+# the QR code is only printed with the text format
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format csv --qr 2>&1 | grep -F -- "--qr cannot be used with --format csv"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format json --qr-out /tmp/ewoq.qr.png 2>&1 | grep -F -- "--qr-out cannot be used with --format json"
+test -s /tmp/ewoq.qr.png
+echo 0x56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027 > /tmp/ewoq.hex.key
+go run ./key-info-validate/main.go /tmp/ewoq.hex.key 9999 --key-format hex
+# MetaMask import format, only with the explicit confirmation
+go run ./key-info-validate/main.go export-eth-key ../artifacts/ewoq.key.json 2>&1 | grep -- "--i-understand-this-exposes-my-key"
+test "$(go run ./key-info-validate/main.go export-eth-key ../artifacts/ewoq.key.json --i-understand-this-exposes-my-key)" = "$(cat /tmp/ewoq.hex.key)"
+# --redact-log redacts the private keys printed by any mode, on stdout and stderr
+test "$(go run ./key-info-validate/main.go --redact-log export-eth-key ../artifacts/ewoq.key.json --i-understand-this-exposes-my-key)" = "0x5628...8027"
+test "$(go run ./key-info-validate/main.go --redact-log ewoq 12345 | grep -c ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN)" -eq 0
+go run ./key-info-validate/main.go --redact-log ewoq 12345 | grep "x_address: X-local18jma8ppw3nhx5r4ap8clazz0dps7rv5u00z96u"
+sed 's/"x_address": "X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p"/"x_address": "56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027"/' ../artifacts/ewoq.key.json > /tmp/ewoq.leak.key.json
+go run ./key-info-validate/main.go --redact-log /tmp/ewoq.leak.key.json 9999 2>&1 | grep -F 'x_address (stored "5628...8027"'
+test "$(go run ./key-info-validate/main.go --redact-log /tmp/ewoq.leak.key.json 9999 2>&1 | grep -c 56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027)" -eq 0
+# unless --show-secret asks for the private keys in full
+go run ./key-info-validate/main.go --redact-log ../artifacts/ewoq.key.json 9999 --show-secret | grep PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN
+# same as "subnet-cli/.insecure.ewoq.key"
+go run ./key-info-validate/main.go ../artifacts/ewoq.subnet-cli.key 9999 --key-format subnet-cli --out /tmp/ewoq.subnet-cli.key.json --force
+diff /tmp/ewoq.subnet-cli.key.json ../artifacts/ewoq.key.json
+go run ./key-info-validate/main.go verify-address ../artifacts/ewoq.key.json X-avax18jma8ppw3nhx5r4ap8clazz0dps7rv5ukulre5
+go run ./key-info-validate/main.go verify-address ../artifacts/ewoq.key.json P-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p
+go run ./key-info-validate/main.go sign ../artifacts/ewoq.key.json 9999 "hello world"
+# "personal_sign" test vector from the web3.js "web3.eth.accounts.sign" docs
+go run ./key-info-validate/main.go eth-sign ../artifacts/web3js.key.json "Some data" | grep 0xb91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c
+go run ./key-info-validate/main.go eth-verify 0x2c7536E3605D9C16a7a3D7b1898e529396a65c23 "Some data" 0xb91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c
+go run ./key-info-validate/main.go verify-signature X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p "hello world" 3Vj2ZoCpoJh74uTh7HNEsJ4QchTyQ6MvQHpeptCAoo4AHhPrJMsakWdPvbZGLxtBaoQzNmJy6jGbaYEHqupsRtvqAu1C6RX
+echo "insecure test passphrase" > /tmp/test.passphrase
+go run ./key-info-validate/main.go export-keystore ../artifacts/ewoq.key.json /tmp/ewoq.keystore.json --passphrase-file /tmp/test.passphrase --force
+go run ./key-info-validate/main.go import-keystore /tmp/ewoq.keystore.json 9999 --passphrase-file /tmp/test.passphrase --key-file ../artifacts/ewoq.key.json
+# self-test of the build
+go run ./key-info-validate/main.go doctor | grep -E "^SUCCESS$"
+test "$(go run ./key-info-validate/main.go doctor | grep -c FAIL)" -eq 0
+# SEC1 and PKCS#8 PEM round-trip, with the same addresses
+go run ./key-info-validate/main.go export-pem ../artifacts/ewoq.key.json /tmp/ewoq.pem --force
+go run ./key-info-validate/main.go import-pem /tmp/ewoq.pem 9999 --key-file ../artifacts/ewoq.key.json
+go run ./key-info-validate/main.go export-pem ../artifacts/ewoq.key.json /tmp/ewoq.pkcs8.pem --pkcs8 --force
+go run ./key-info-validate/main.go import-pem /tmp/ewoq.pkcs8.pem 9999 --key-file ../artifacts/ewoq.key.json
+if command -v openssl >/dev/null; then
+  # byte-identical to the OpenSSL encodings of the same key
+  openssl ec -in /tmp/ewoq.pem | cmp - /tmp/ewoq.pem
+  openssl pkcs8 -topk8 -nocrypt -in /tmp/ewoq.pem | cmp - /tmp/ewoq.pkcs8.pem
+  openssl ecparam -name secp256k1 -genkey -out /tmp/openssl.secp256k1.pem
+  go run ./key-info-validate/main.go import-pem /tmp/openssl.secp256k1.pem 9999
+  openssl ecparam -name prime256v1 -genkey -noout -out /tmp/openssl.p256.pem
+  go run ./key-info-validate/main.go import-pem /tmp/openssl.p256.pem 9999 2>&1 | grep "PEM key is not a secp256k1 key"
+fi
+# passphrase-encrypted key file, decrypted in memory on read
+go run ./key-info-validate/main.go encrypt-file ../artifacts/ewoq.key.json /tmp/ewoq.encrypted.json --passphrase-file /tmp/test.passphrase --force
+test "$(grep -c "private_key" /tmp/ewoq.encrypted.json)" -eq 0
+go run ./key-info-validate/main.go /tmp/ewoq.encrypted.json 9999 --key-passphrase-file /tmp/test.passphrase
+KEYSTORE_PASSPHRASE="insecure test passphrase" go run ./key-info-validate/main.go - 9999 < /tmp/ewoq.encrypted.json
+echo "wrong passphrase" > /tmp/wrong.passphrase
+go run ./key-info-validate/main.go /tmp/ewoq.encrypted.json 9999 --key-passphrase-file /tmp/wrong.passphrase 2>&1 | grep "could not decrypt the key file (wrong passphrase, or the file is corrupted)"
+# a crafted envelope cannot make the decryption run out of memory or for hours, or use a short salt
+cat > /tmp/evil.key.json <<EOF
+{"version":1,"cipher":"aes-256-gcm","kdf":"scrypt","scrypt_n":1048576,"scrypt_r":32768,"scrypt_p":1,"salt":"$(printf '%032d' 0)","nonce":"$(printf '%024d' 0)","ciphertext":"00"}
+EOF
+KEYSTORE_PASSPHRASE=x go run ./key-info-validate/main.go /tmp/evil.key.json 9999 2>&1 | grep -F "encrypted key file scrypt parameters N=1048576, r=32768 exceed the memory limit of 1073741824 bytes"
+sed 's/"scrypt_n":1048576,"scrypt_r":32768,"scrypt_p":1/"scrypt_n":262144,"scrypt_r":8,"scrypt_p":1073741823/' /tmp/evil.key.json > /tmp/evil.p.key.json
+KEYSTORE_PASSPHRASE=x go run ./key-info-validate/main.go /tmp/evil.p.key.json 9999 2>&1 | grep -F "exceed the work limit"
+sed 's/"scrypt_n":1048576,"scrypt_r":32768/"scrypt_n":262144,"scrypt_r":8/; s/"salt":"[0-9]*"/"salt":"00"/' /tmp/evil.key.json > /tmp/evil.salt.key.json
+KEYSTORE_PASSPHRASE=x go run ./key-info-validate/main.go /tmp/evil.salt.key.json 9999 2>&1 | grep -F "invalid encrypted key file salt (got 1 bytes, expected at least 16 bytes)"
+go run ./key-info-validate/main.go /tmp/ewoq.encrypted.json 9999 2>&1 | grep "no passphrase for the encrypted key file"
+# rewrites keep the key file encrypted
+cp /tmp/ewoq.encrypted.json /tmp/ewoq.encrypted.migrate.json
+go run ./key-info-validate/main.go /tmp/ewoq.encrypted.migrate.json 9999 --key-passphrase-file /tmp/test.passphrase --migrate
+go run ./key-info-validate/main.go /tmp/ewoq.encrypted.json 9999 --key-passphrase-file /tmp/test.passphrase --canonicalize 2>&1 | grep "requires --out, so the private key is not printed"
+test "$(grep -c "private_key" /tmp/ewoq.encrypted.migrate.json)" -eq 0
+go run ./key-info-validate/main.go /tmp/ewoq.encrypted.migrate.json 9999 --key-passphrase-file /tmp/test.passphrase
+# mutate the last checksum character of the private key
+sed 's/TXtNN"/TXtNM"/' ../artifacts/ewoq.key.json > /tmp/ewoq.corrupted.key.json
+go run ./key-info-validate/main.go /tmp/ewoq.corrupted.key.json 9999 2>&1 | grep "CB58 checksum failed"
+# hand-edit "private_key_hex" without updating "private_key"
+sed 's/"private_key_hex": "56289e99/"private_key_hex": "56289e98/' ../artifacts/ewoq.key.json > /tmp/ewoq.mismatch.key.json
+go run ./key-info-validate/main.go /tmp/ewoq.mismatch.key.json 9999 2>&1 | grep "private_key_hex is inconsistent"
+# JSON array of key info objects, with the invalid elements reported by index
+(echo "["; cat ../artifacts/ewoq.key.json; echo ","; cat ../artifacts/ewoq.legacy.key.json; echo "]") > /tmp/keys.json
+go run ./key-info-validate/main.go validate-array /tmp/keys.json 9999
+(echo "["; cat ../artifacts/ewoq.key.json; echo ","; cat /tmp/ewoq.mismatch.key.json; echo ", 42]") > /tmp/keys.mixed.json
+go run ./key-info-validate/main.go validate-array /tmp/keys.mixed.json 9999 | grep -E "^1 +X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p +FAIL"
+go run ./key-info-validate/main.go validate-array /tmp/keys.mixed.json 9999 2>&1 | grep "2 of 3 keys failed validation"
+test "$(go run ./key-info-validate/main.go validate-array /tmp/keys.mixed.json 9999 --format csv --no-header | cut -d , -f 1)" = "0"
+# truncated JSON, and YAML indented with a tab
+head -c 300 ../artifacts/ewoq.key.json > /tmp/ewoq.truncated.key.json
+go run ./key-info-validate/main.go /tmp/ewoq.truncated.key.json 9999 2>&1 | grep "invalid JSON key file at line 5, column 57 (unexpected end of JSON input)"
+go run ./key-info-validate/main.go ewoq 9999 | sed 's/^p_address/\tp_address/' > /tmp/ewoq.tab.key.yaml
+go run ./key-info-validate/main.go /tmp/ewoq.tab.key.yaml 9999 2>&1 | grep "invalid YAML key file (yaml: line 3: found a tab character"
+# the fields of a wrong type or missing are reported by name, before decoding
+echo '{"private_key": 5, "x_address": "X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p", "p_address": "P-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p"}' > /tmp/ewoq.schema.key.json
+go run ./key-info-validate/main.go /tmp/ewoq.schema.key.json 9999 2>&1 | grep -F "key file does not match the key info schema: c_address: missing required field, private_key: expected a string, got a number"
+printf 'private_key: PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN\nx_address: X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p\np_address: P-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p\nc_address: C-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p\nnetwork_id: "9999"\n' > /tmp/ewoq.schema.key.yaml
+go run ./key-info-validate/main.go /tmp/ewoq.schema.key.yaml 9999 2>&1 | grep -F "network_id: expected an integer, got a string"
+go run ./key-info-validate/main.go /tmp/ewoq.schema.key.json 9999 --skip-schema 2>&1 | grep -F "cannot unmarshal number into Go struct field Info.private_key of type string"
+# zero, oversized, and out-of-range (secp256k1 N) private keys
+echo PrivateKey-11111111111111111111111111111111LpoYY > /tmp/zero.key
+go run ./key-info-validate/main.go /tmp/zero.key 9999 --key-format subnet-cli 2>&1 | grep "invalid SECP256K1R private key (scalar out of range"
+# ewoq private key with an extra 0x00 byte
+echo PrivateKey-3tUuk64bEL88ZuUySuMx3qUTgkibLNB74ShrZXYvRcWDrh1q8P1 > /tmp/oversized.key
+go run ./key-info-validate/main.go /tmp/oversized.key 9999 --key-format subnet-cli 2>&1 | grep "invalid SECP256K1R private key (got 33 bytes, expected 32 bytes)"
+echo 0xfffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141 > /tmp/curve-order.hex.key
+go run ./key-info-validate/main.go /tmp/curve-order.hex.key 9999 --key-format hex 2>&1 | grep "invalid SECP256K1R private key (scalar out of range"
+# base64 private key, in the standard and the URL-safe alphabets
+echo "ViiemclLaRK/wSrcCTybURJPDcVKx6dmsrxcz1WNgCc=" > /tmp/ewoq.base64.key
+echo "ViiemclLaRK_wSrcCTybURJPDcVKx6dmsrxcz1WNgCc" > /tmp/ewoq.base64url.key
+go run ./key-info-validate/main.go /tmp/ewoq.base64.key 9999 --key-format base64 --assert-x X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p
+go run ./key-info-validate/main.go /tmp/ewoq.base64url.key 9999 --key-format base64 --assert-x X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p
+echo "ViiemclLaRK/wSrcCTybURJPDcVKx6dmsrxcz1WNgA==" > /tmp/short.base64.key
+go run ./key-info-validate/main.go /tmp/short.base64.key 9999 --key-format base64 2>&1 | grep "invalid base64 private key length 31 bytes (expected 32 bytes)"
+# the AWS secret stores are only built in with "-tags aws"
+go run ./key-info-validate/main.go 9999 --key-source ssm:///avalanche/keys/ewoq 2>&1 | grep 'key-source ssm:// is not built in, rebuild with "-tags aws"'
+go run ./key-info-validate/main.go 9999 --key-source vault://ewoq 2>&1 | grep 'unknown --key-source scheme "vault"'
+# the private key in an environment variable, in the --key-format encodings, never echoed
+AVALANCHE_TEST_KEY=PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN go run ./key-info-validate/main.go 9999 --key-source env:AVALANCHE_TEST_KEY --key-format subnet-cli --log-level debug > /tmp/test-key-source-env.out 2>&1
+grep -x "x_address: X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p" /tmp/test-key-source-env.out
+test "$(grep -c ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN /tmp/test-key-source-env.out)" -eq 0
+AVALANCHE_TEST_KEY=56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027 go run ./key-info-validate/main.go 9999 --key-source env:AVALANCHE_TEST_KEY --key-format hex --quiet | grep -x SUCCESS
+AVALANCHE_TEST_KEY="$(cat ../artifacts/ewoq.key.json)" go run ./key-info-validate/main.go 9999 --key-source env:AVALANCHE_TEST_KEY --quiet | grep -x SUCCESS
+go run ./key-info-validate/main.go 9999 --key-source env:AVALANCHE_UNSET_KEY 2>&1 | grep -F "environment variable AVALANCHE_UNSET_KEY is not set"
+AVALANCHE_TEST_KEY= go run ./key-info-validate/main.go 9999 --key-source env:AVALANCHE_TEST_KEY 2>&1 | grep -F "environment variable AVALANCHE_TEST_KEY is empty"
+go vet -tags aws ./key-info-validate/
+# the balance lookup is only built in with "-tags balance", and never fails the validation
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --check-balance 2>&1 | grep 'check-balance is not built in, rebuild with "-tags balance"'
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --endpoint http://127.0.0.1:19650 2>&1 | grep -F -- "--endpoint requires --check-balance"
+go vet -tags balance ./key-info-validate/
+go run -tags balance ./key-info-validate/ ../artifacts/ewoq.key.json 9999 --check-balance --endpoint http://127.0.0.1:1 > /tmp/test-check-balance.out 2>&1
+grep "WARN: --check-balance: failed to fetch the balances from \"http://127.0.0.1:1\"" /tmp/test-check-balance.out
+grep -x SUCCESS /tmp/test-check-balance.out
+go run -tags balance ./key-info-validate/ --offline ../artifacts/ewoq.key.json 9999 --check-balance 2>&1 | grep -F "error: --check-balance may access the network"
+# --offline fails every feature that may access the network with exit code 2, and allows the rest
+go run ./key-info-validate/main.go --offline generate 1 /tmp/offline.key.json --force
+go run ./key-info-validate/main.go --offline /tmp/offline.key.json 1 --quiet | grep -x SUCCESS
+AVALANCHE_TEST_KEY="$(cat ../artifacts/ewoq.key.json)" go run ./key-info-validate/main.go --offline 9999 --key-source env:AVALANCHE_TEST_KEY --quiet | grep -x SUCCESS
+go run ./key-info-validate/main.go --offline 9999 --key-source ssm:///avalanche/keys/ewoq 2>&1 | grep -F "error: --key-source ssm:// may access the network (network access is disabled by --offline)"
+go run ./key-info-validate/main.go 9999 --key-source secretsmanager://ewoq --offline 2>&1 | grep -F "exit status 2"
+go run ./key-info-validate/main.go --offline ../artifacts/ewoq.key.json 9999 --compare-tool cat 2>&1 | grep -F "error: --compare-tool may access the network"
+# the global flags are only taken before the first positional argument of the mode, or as its flags;
+# after "--", they are positional (e.g., the message to sign)
+printf -- '--offline' > /tmp/offline.msg
+test "$(go run ./key-info-validate/main.go sign ../artifacts/ewoq.key.json 9999 -- --offline)" = "$(go run ./key-info-validate/main.go sign ../artifacts/ewoq.key.json 9999 --message-file /tmp/offline.msg)"
+go run ./key-info-validate/main.go sign ../artifacts/ewoq.key.json 9999 --offline 2>&1 | grep -F "error: expected 1 message argument or --message-file, got []"
+go run ./key-info-validate/main.go --log-level debug sign ../artifacts/ewoq.key.json 9999 -- --log-level 2>&1 | grep -F 'DEBUG: reading "../artifacts/ewoq.key.json"'
+go run ./key-info-validate/main.go explain ../artifacts/ewoq.key.json 9999 --log-level error | grep "public key (33-byte compressed secp256k1)"
+# long inputs are rejected before the quadratic base58 decoding
+printf 'PrivateKey-%0200000d\n' 0 | tr 0 z > /tmp/long.key
+go run ./key-info-validate/main.go /tmp/long.key 9999 --key-format subnet-cli 2>&1 | grep "invalid SECP256K1R private key (got 200000 characters, expected at most 98 characters)"
+popd
+
+###
+pushd ./compatibility
+# BIP39 test mnemonic, derived at "m/44'/9000'/0'/0/0"
+go run ./key-info-validate/main.go from-mnemonic "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about" 0 1 > /tmp/mnemonic.key.yaml
+diff /tmp/mnemonic.key.yaml ../artifacts/mnemonic.abandon.0.key.yaml
+go run ./key-info-validate/main.go /tmp/mnemonic.key.yaml 1
+go run ./key-info-validate/main.go /tmp/mnemonic.key.yaml 1 --strict-network
+# first MetaMask account of the same mnemonic at "m/44'/60'/0'/0/0"
+go run ./key-info-validate/main.go from-mnemonic "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about" 0 1 --eth-accounts 3 > /tmp/mnemonic.eth-accounts.key.yaml
+grep -A1 "^- eth_address: 0x9858EfFD232B4033E47d90003D41EC34EcaEda94" /tmp/mnemonic.eth-accounts.key.yaml | grep "index: 0"
+go run ./key-info-validate/main.go /tmp/mnemonic.eth-accounts.key.yaml 1 --strict
+# BIP32 root extended private key of the same mnemonic
+go run ./key-info-validate/main.go from-mnemonic xprv9s21ZrQH143K3GJpoapnV8SFfukcVBSfeCficPSGfubmSFDxo1kuHnLisriDvSnRRuL2Qrg5ggqHKNVpxR86QEC8w35uxmGoggxtQTPvfUu 0 1 --eth-accounts 3 > /tmp/xprv.key.yaml
+diff /tmp/xprv.key.yaml /tmp/mnemonic.eth-accounts.key.yaml
+
+go run ./key-info-validate/main.go from-mnemonic "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about" 0 1 --hd-range 0-9 > /tmp/mnemonic.hd-range.key.yaml
+test "$(grep -c "^  index: " /tmp/mnemonic.hd-range.key.yaml)" -eq 10
+grep -B4 "path: m/44'/9000'/0'/0/3" /tmp/mnemonic.hd-range.key.yaml | grep "index: 3"
+grep -A5 "^- c_address: " /tmp/mnemonic.hd-range.key.yaml | grep "x_address: $(grep '^x_address: ' /tmp/mnemonic.hd-range.key.yaml | cut -d' ' -f2)"
+go run ./key-info-validate/main.go /tmp/mnemonic.hd-range.key.yaml 1 --strict
+go run ./key-info-validate/main.go from-mnemonic xprv9s21ZrQH143K3GJpoapnV8SFfukcVBSfeCficPSGfubmSFDxo1kuHnLisriDvSnRRuL2Qrg5ggqHKNVpxR86QEC8w35uxmGoggxtQTPvfUu 0 1 --hd-range 0-9 | diff - /tmp/mnemonic.hd-range.key.yaml
+go run ./key-info-validate/main.go from-mnemonic "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about" 0 1 --hd-range 0-x 2>&1 | grep "invalid --hd-range"
+go run ./key-info-validate/main.go from-mnemonic "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about" 0 1 --hd-range 9-0 2>&1 | grep "the first index is greater than the last"
+go run ./key-info-validate/main.go from-mnemonic "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about" 0 1 --hd-range 0-1000 2>&1 | grep "expected at most 1000"
+# account extended public key of the same mnemonic at "m/44'/9000'/0'"
+go run ./key-info-validate/main.go from-xpub xpub6BqVigHfL2TNNs8HyeEHn4JFFyTw1vL8kC5ZhzBhrhDbQ3FhgakpivT97Cd7oVCJiAwiqWu313vKMZMwCghXgSVDnYR3FrYzTz24yY3nFHR 0 1 > /tmp/xpub.key.yaml
+go run ./key-info-validate/main.go /tmp/xpub.key.yaml 1
+diff <(grep _address /tmp/xpub.key.yaml) <(grep _address ../artifacts/mnemonic.abandon.0.key.yaml)
+popd
+
+###
+pushd ./compatibility
+# known ewoq addresses on each network ID, derived from both the CB58 and hex private keys
+echo 0x56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027 > /tmp/ewoq.hex.key
+tail -n +2 ../artifacts/ewoq.addresses.csv | while IFS= read -r row; do
+  network_id=${row%%,*}
+  go run ./key-info-validate/main.go ewoq ${network_id} > /tmp/ewoq.${network_id}.key.yaml
+  test "$(go run ./key-info-validate/main.go /tmp/ewoq.${network_id}.key.yaml ${network_id} --format csv --no-header)" = "${row}"
+  test "$(go run ./key-info-validate/main.go /tmp/ewoq.hex.key ${network_id} --key-format hex --format csv --no-header)" = "${row}"
+done
+# the HRP of each network ID (constants.GetHRP), and the "custom" fallback for the others
+tail -n +2 ../artifacts/network.hrps.csv | while IFS=, read -r network_id hrp; do
+  go run ./key-info-validate/main.go ewoq ${network_id} > /tmp/ewoq.hrp.key.yaml
+  grep "x_address: X-${hrp}1" /tmp/ewoq.hrp.key.yaml
+  go run ./key-info-validate/main.go /tmp/ewoq.hrp.key.yaml ${network_id} | grep "HRP \"${hrp}\""
+  go run ./key-info-validate/main.go /tmp/ewoq.hrp.key.yaml ${network_id} --format json --json-compact | grep "\"hrp\":\"${hrp}\""
+done
+# warns when a mistyped network ID falls back to "custom" for the key of a standard network
+go run ./key-info-validate/main.go /tmp/ewoq.5.key.yaml 50 2>&1 | grep 'network ID 50 is not a standard network and falls back to HRP "custom", but the addresses use HRP "fuji" of fuji (5) (use network ID 5, or --hrp fuji)'
+test "$(go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 2>&1 | grep -c "falls back to HRP")" -eq 0
+# warns loudly when a mainnet key is used for a testnet or vice versa, and fails with --strict
+go run ./key-info-validate/main.go ewoq 1 > /tmp/ewoq.1.key.yaml
+go run ./key-info-validate/main.go /tmp/ewoq.1.key.yaml 5 2>&1 | grep -F 'MAINNET KEY: the addresses use the mainnet HRP "avax", but the key is used for fuji (5) with HRP "fuji"'
+go run ./key-info-validate/main.go /tmp/ewoq.1.key.yaml 5 --strict 2>&1 | grep -F 'error: MAINNET KEY:'
+go run ./key-info-validate/main.go /tmp/ewoq.5.key.yaml 1 2>&1 | grep -F 'TESTNET KEY ON MAINNET: the addresses use HRP "fuji", but the key is used for mainnet (1) with HRP "avax"'
+# the mix-up is reported by exactly one warning before the error
+go run ./key-info-validate/main.go /tmp/ewoq.1.key.yaml 5 2>&1 | grep -c "WARN:" | grep -x 1
+go run ./key-info-validate/main.go /tmp/ewoq.5.key.yaml 1 2>&1 | grep -c "WARN:" | grep -x 1
+go run ./key-info-validate/main.go /tmp/ewoq.5.key.yaml --network mainnet --strict 2>&1 | grep -F "exit status 5"
+test "$(go run ./key-info-validate/main.go /tmp/ewoq.1.key.yaml 1 2>&1 | grep -c "KEY")" -eq 0
+test "$(go run ./key-info-validate/main.go /tmp/ewoq.5.key.yaml 50 2>&1 | grep -c "MAINNET")" -eq 0
+# a key file valid for another network than the requested one exits with code 5, not 1
+go run ./key-info-validate/main.go /tmp/ewoq.1.key.yaml 5 2>&1 | grep -F 'the key file is valid, but for mainnet (1) with HRP "avax", not for fuji (5) with HRP "fuji" (use network ID 1'
+go run ./key-info-validate/main.go /tmp/ewoq.1.key.yaml 5 2>&1 | grep -F "exit status 5"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 12345 2>&1 | grep -F 'the key file is valid, but for a custom network with HRP "custom", not for local (12345) with HRP "local" (use a custom network ID or --hrp custom'
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 12345 2>&1 | grep -F "exit status 5"
+go run ./key-info-validate/main.go /tmp/ewoq.wrong-x.key.json 1 2>&1 | grep -F "exit status 1"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 12345 --hrp custom --quiet | grep -x SUCCESS
+# CB58 round-trip, and the same addresses from the hex encoding of each key
+while IFS= read -r key || [ -n "${key}" ]; do
+  echo ${key} > /tmp/round-trip.key
+  go run ./key-info-validate/main.go /tmp/round-trip.key 9999 --key-format subnet-cli --out /tmp/round-trip.key.json --force
+  grep "\"private_key\": \"${key}\"" /tmp/round-trip.key.json
+  grep private_key_hex /tmp/round-trip.key.json | cut -d '"' -f 4 > /tmp/round-trip.hex.key
+  test "$(go run ./key-info-validate/main.go /tmp/round-trip.hex.key 9999 --key-format hex --format csv)" = "$(go run ./key-info-validate/main.go /tmp/round-trip.key.json 9999 --format csv)"
+done < ../artifacts/test.insecure.secp256k1.keys
+popd
+
 ###
 pushd ./compatibility
 # copied from "avalanchego/staking/local/staking1.key,crt"
 go run ./node-id-load/main.go ../artifacts/staker1.insecure.key ../artifacts/staker1.insecure.crt
+# same 20-byte hash as the short address
+test "$(go run ./key-info-validate/main.go short-to-nodeid 7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg)" = "$(go run ./node-id-load/main.go ../artifacts/staker1.insecure.key ../artifacts/staker1.insecure.crt)"
+test "$(go run ./key-info-validate/main.go nodeid-to-short NodeID-7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg)" = "7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg"
 go run ./node-id-load/main.go ../artifacts/staker2.insecure.key ../artifacts/staker2.insecure.crt
 go run ./node-id-load/main.go ../artifacts/staker3.insecure.key ../artifacts/staker3.insecure.crt
 go run ./node-id-load/main.go ../artifacts/staker4.insecure.key ../artifacts/staker4.insecure.crt
@@ -45,5 +567,51 @@ go run ./node-id-load/main.go /tmp/test.insecure.key /tmp/test.insecure.cert
 popd
 cargo run --example utils_cert -- /tmp/test.insecure.key /tmp/test.insecure.cert
 
+###
+pushd ./compatibility
+# the staking key pair and the wallet key of a node, and the NodeID derives
+# from the generated certificate, same as avalanchego and on every load
+rm -rf /tmp/test-staker
+go run ./key-info-validate/main.go generate-staker /tmp/test-staker 9999 > /tmp/test-staker.out
+test "$(head -1 /tmp/test-staker.out)" = "$(go run ./node-id-load/main.go /tmp/test-staker/staker.key /tmp/test-staker/staker.crt)"
+test "$(go run ./node-id-load/main.go /tmp/test-staker/staker.key /tmp/test-staker/staker.crt)" = "$(go run ./node-id-load/main.go /tmp/test-staker/staker.key /tmp/test-staker/staker.crt)"
+test "$(sed -n 2p /tmp/test-staker.out)" = "$(grep x_address /tmp/test-staker/wallet.key.json | cut -d '"' -f 4)"
+go run ./key-info-validate/main.go validate-dir /tmp/test-staker 9999
+go run ./key-info-validate/main.go generate-staker /tmp/test-staker 2>&1 | grep -F "already exists (use --force to overwrite)"
+# without the wallet key, only the NodeID is printed
+go run ./key-info-validate/main.go generate-staker /tmp/test-staker --force > /tmp/test-staker.out
+test "$(cat /tmp/test-staker.out | wc -l)" -eq 1
+test "$(cat /tmp/test-staker.out)" = "$(go run ./node-id-load/main.go /tmp/test-staker/staker.key /tmp/test-staker/staker.crt)"
+# another key tool computes the same addresses for the same key, and any
+# address it computes differently fails with exit code 1
+go build -o /tmp/test-compare-tool ./key-info-validate
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --compare-tool /tmp/test-compare-tool | grep -E "^x_address +X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p +X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p +SAME"
+cat > /tmp/test-compare-tool.sh <<EOF
+#!/usr/bin/env bash
+/tmp/test-compare-tool "\$@" | sed "s/X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p/$(grep x_address /tmp/test-keys/1.key.json | cut -d '"' -f 4)/"
+EOF
+chmod +x /tmp/test-compare-tool.sh
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --compare-tool /tmp/test-compare-tool.sh 2>&1 | grep -E "^x_address .+ DIFFERENT"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --compare-tool /tmp/test-compare-tool.sh 2>&1 | grep -F "computed 1 different addresses"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --compare-tool /tmp/test-compare-tool.sh 2>&1 | grep -F "exit status 1"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --compare-tool true 2>&1 | grep -F "printed no addresses"
+# a tool that also prints the addresses of other networks computes the same addresses
+cat > /tmp/test-compare-tool-networks.sh <<EOF
+#!/usr/bin/env bash
+/tmp/test-compare-tool "\$@" --networks 1,5
+EOF
+chmod +x /tmp/test-compare-tool-networks.sh
+/tmp/test-compare-tool-networks.sh ../artifacts/ewoq.key.json 9999 | grep -F X-avax18jma8ppw3nhx5r4ap8clazz0dps7rv5ukulre5
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --compare-tool /tmp/test-compare-tool-networks.sh | grep -E "^x_address +X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p +.*X-avax18jma8ppw3nhx5r4ap8clazz0dps7rv5ukulre5.* +SAME"
+test "$(go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --compare-tool /tmp/test-compare-tool-networks.sh | grep -c DIFFERENT)" -eq 0
+# every --format runs the comparison, with the table on stderr so the output stays parseable
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format json --compare-tool false 2>&1 | grep -F 'error: --compare-tool "false" failed'
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format json --compare-tool false 2>&1 | grep -F "exit status 1"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format json --json-compact --compare-tool false 2>/dev/null | grep -F '"valid":false'
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format csv --compare-tool /tmp/test-compare-tool.sh 2>&1 | grep -F "computed 1 different addresses"
+go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format env --compare-tool /tmp/test-compare-tool 2>&1 >/dev/null | grep -E "^x_address .+ SAME"
+test "$(go run ./key-info-validate/main.go ../artifacts/ewoq.key.json 9999 --format env --compare-tool /tmp/test-compare-tool 2>/dev/null | grep -c SAME)" -eq 0
+popd
+
 ###
 echo "ALL SUCCESS!"
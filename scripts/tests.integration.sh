@@ -0,0 +1,16 @@
+#!/usr/bin/env bash
+set -xue
+
+if ! [[ "$0" =~ scripts/tests.integration.sh ]]; then
+  echo "must be run from repository root"
+  exit 255
+fi
+
+# requires a running avalanchego with "--api-keystore-enabled", skipped otherwise
+# e.g., AVALANCHEGO_ENDPOINT=http://127.0.0.1:9650 scripts/tests.integration.sh
+pushd ./compatibility
+go vet -tags integration ./key-info-integration/
+go run -tags integration ./key-info-integration/main.go
+popd
+
+echo "ALL SUCCESS!"